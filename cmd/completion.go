@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/morethancertified/mtc-cli/internal/config"
+	"github.com/morethancertified/mtc-cli/internal/history"
+	"github.com/morethancertified/mtc-cli/internal/lab"
+	"github.com/spf13/cobra"
+)
+
+// labSearchDepth is how deep below the workspace root completion looks for
+// labs, enough for templates such as {{.Course.Title}}/{{.Title}}
+const labSearchDepth = 3
+
+// completeLessonToken completes a lesson token as the only positional
+// argument
+func completeLessonToken(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return lessonTokenCompletions(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// lessonTokenCompletions returns the lesson tokens of the lab around the
+// working directory, the labs under the workspace root and the lessons in
+// the submission history, described by their lab title where it is known
+func lessonTokenCompletions() []string {
+	var tokens []string
+	descriptions := map[string]string{}
+	add := func(token, description string) {
+		if token == "" {
+			return
+		}
+		current, seen := descriptions[token]
+		if !seen {
+			tokens = append(tokens, token)
+		}
+		if current == "" {
+			descriptions[token] = description
+		}
+	}
+
+	workspace, _ := workspaceRoot()
+	if wd, err := os.Getwd(); err == nil {
+		if root, found, _ := lab.FindRoot(wd, workspace); found {
+			add(labToken(root))
+		}
+	}
+
+	if workspace != "" {
+		for _, dir := range findLabs(workspace) {
+			add(labToken(dir))
+		}
+	}
+
+	if historyTokens, submitted, err := history.Tokens(); err == nil {
+		for _, token := range historyTokens {
+			add(token, "last submitted "+submitted[token].Format("2006-01-02"))
+		}
+	}
+
+	completions := make([]string, len(tokens))
+	for i, token := range tokens {
+		completions[i] = withDescription(token, descriptions[token])
+	}
+	return completions
+}
+
+// labToken returns the lesson token and title of the lab in dir, either of
+// which may be empty
+func labToken(dir string) (string, string) {
+	values, err := lab.ReadConfig(dir)
+	if err != nil {
+		return "", ""
+	}
+	meta, _, _ := lab.ReadMetadata(dir)
+
+	token, _ := values[lab.LessonTokenKey].(string)
+	if token == "" {
+		token = meta.UserLessonID
+	}
+	return token, meta.Title
+}
+
+// findLabs returns the lab directories up to labSearchDepth below root. The
+// search does not descend into labs or the directories watch ignores, and
+// skips directories it cannot read.
+func findLabs(root string) []string {
+	var dirs []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			cobra.CompDebugln(fmt.Sprintf("skipping %s: %s", path, err), false)
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && lab.Ignored(root, path) {
+			return filepath.SkipDir
+		}
+		if _, err := os.Stat(filepath.Join(path, lab.ConfigFile)); err == nil {
+			dirs = append(dirs, path)
+			return filepath.SkipDir
+		}
+		rel, _ := filepath.Rel(root, path)
+		if rel != "." && strings.Count(rel, string(filepath.Separator))+1 >= labSearchDepth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		cobra.CompDebugln(fmt.Sprintf("searching %s for labs: %s", root, err), false)
+	}
+	return dirs
+}
+
+// completeProfileNames completes the name of a profile in the global
+// config
+func completeProfileNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	profiles, _, err := readProfiles()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, name := range config.ProfileNames(profiles) {
+		completions = append(completions, withDescription(name, profiles[name].APIBaseURL))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeHistoryDiff completes the lesson token after the two attempt
+// numbers of history diff
+func completeHistoryDiff(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 2 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return lessonTokenCompletions(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeConfigKey completes the name of a setting as the first argument,
+// described by what it does. The workspace root value of config set
+// completes directories.
+func completeConfigKey(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 1 && cmd.Name() == "set" && args[0] == "workspace_root" {
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	}
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	completions := make([]string, 0, len(config.Keys))
+	for _, key := range config.Keys {
+		completions = append(completions, withDescription(key.Name, key.Description))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// withDescription attaches a description to a completion, which shells
+// that support it show next to the candidate
+func withDescription(completion, description string) string {
+	if description == "" {
+		return completion
+	}
+	return completion + "\t" + description
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/morethancertified/mtc-cli/internal/history"
+	"github.com/morethancertified/mtc-cli/internal/lab"
+	"github.com/morethancertified/mtc-cli/internal/types"
+	"github.com/spf13/viper"
+)
+
+const (
+	tokenA = "caaaaaaaaaaaaaaaaaaaaaaaa"
+	tokenB = "cbbbbbbbbbbbbbbbbbbbbbbbb"
+	tokenC = "ccccccccccccccccccccccccc"
+	tokenD = "cdddddddddddddddddddddddd"
+	tokenE = "ceeeeeeeeeeeeeeeeeeeeeeee"
+)
+
+func TestLessonTokenCompletions(t *testing.T) {
+	base := t.TempDir()
+	workspace := filepath.Join(base, "workspace")
+	t.Setenv("HOME", filepath.Join(base, "home"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(base, "data"))
+	viper.Set("workspace_root", workspace)
+	t.Cleanup(viper.Reset)
+
+	// A lab with a token but no title, one inside a dot-directory known only
+	// by its marker and one in a directory completion ignores
+	newLab(t, filepath.Join(workspace, "Course", "Lab_One"), tokenA, nil)
+	newLab(t, filepath.Join(workspace, ".labs", "Lab_Two"), "", &types.LabMetadata{UserLessonID: tokenB, Title: "Lab Two"})
+	newLab(t, filepath.Join(workspace, "node_modules", "pkg"), tokenC, nil)
+
+	now := time.Now()
+	for i, token := range []string{tokenE, tokenA, tokenD} {
+		recordAttempt(t, token, now.Add(time.Duration(i-3)*time.Hour))
+	}
+
+	wd := filepath.Join(workspace, "Course", "Lab_One", "modules")
+	if err := os.MkdirAll(wd, 0755); err != nil {
+		t.Fatal(err)
+	}
+	chdir(t, wd)
+
+	submitted := func(i int) string {
+		return "last submitted " + now.Add(time.Duration(i-3)*time.Hour).Format("2006-01-02")
+	}
+	want := []string{
+		tokenA + "\t" + submitted(1),
+		tokenB + "\tLab Two",
+		tokenD + "\t" + submitted(2),
+		tokenE + "\t" + submitted(0),
+	}
+
+	got := lessonTokenCompletions()
+	if len(got) != len(want) {
+		t.Fatalf("got completions %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("completion %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestFindLabsMissingRoot(t *testing.T) {
+	if dirs := findLabs(filepath.Join(t.TempDir(), "missing")); len(dirs) != 0 {
+		t.Errorf("findLabs found %q in a missing directory", dirs)
+	}
+}
+
+func newLab(t *testing.T, dir, token string, meta *types.LabMetadata) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]interface{}{}
+	if token != "" {
+		values[lab.LessonTokenKey] = token
+	}
+	if err := lab.UpdateConfig(dir, values); err != nil {
+		t.Fatal(err)
+	}
+	if meta != nil {
+		if err := lab.WriteMetadata(dir, *meta); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func recordAttempt(t *testing.T, token string, at time.Time) {
+	t.Helper()
+	if err := history.Record(&history.Attempt{LessonToken: token}); err != nil {
+		t.Fatal(err)
+	}
+	dir, err := history.Dir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(dir, token+".jsonl"), at, at); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/morethancertified/mtc-cli/internal/config"
+	"github.com/morethancertified/mtc-cli/internal/exitcode"
+	"github.com/morethancertified/mtc-cli/internal/lab"
+	"github.com/morethancertified/mtc-cli/internal/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and edit mtc settings",
+	Long: `Inspect and edit mtc settings.
+
+Settings are resolved in order from command line flags, MTC_ environment
+variables, the project config (.mtc.json), the selected profile, the global
+config and built-in defaults. A profile selected with --profile or
+MTC_PROFILE takes precedence over the project config. Use --global or --local to read or write a specific file.`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:               "get <key>",
+	Short:             "Print the value of a setting",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeConfigKey,
+	Example:           "mtc config get api_base_url",
+	Run: func(cmd *cobra.Command, args []string) {
+		result := output.Result{Command: "config get"}
+		defer result.Emit()
+
+		if _, ok := config.Lookup(args[0]); !ok {
+			result.FailWith(exitcode.Usage, "Error: unknown key %q, valid keys are %s", args[0], strings.Join(config.Names(), ", "))
+			return
+		}
+
+		path, err := configScopePath(cmd, false)
+		if err != nil {
+			result.Fail("Error: %s", err)
+			return
+		}
+
+		if path == "" {
+			result.Data = configValue{Key: args[0], Value: viper.Get(args[0])}
+			output.Println(viper.GetString(args[0]))
+			return
+		}
+
+		values, err := config.ReadFile(path)
+		if err != nil {
+			result.Fail("Error reading %s: %s", path, err)
+			return
+		}
+		if value, ok := values[args[0]]; ok {
+			result.Data = configValue{Key: args[0], Value: value, File: path}
+			output.Println(value)
+		}
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:               "set <key> <value>",
+	Short:             "Change a setting (global config unless --local is given)",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeConfigKey,
+	Example:           "mtc config set workspace_root ~/mtc-labs",
+	Run: func(cmd *cobra.Command, args []string) {
+		result := output.Result{Command: "config set"}
+		defer result.Emit()
+
+		key, ok := config.Lookup(args[0])
+		if !ok {
+			result.FailWith(exitcode.Usage, "Error: unknown key %q, valid keys are %s", args[0], strings.Join(config.Names(), ", "))
+			return
+		}
+		if local, _ := cmd.Flags().GetBool("local"); key.Local && !local {
+			result.FailWith(exitcode.Usage, "Error: %s can only be set in the project config, use --local", key.Name)
+			return
+		}
+
+		path, err := configScopePath(cmd, true)
+		if err != nil {
+			result.Fail("Error: %s", err)
+			return
+		}
+
+		values, err := config.ReadFile(path)
+		if err != nil {
+			result.Fail("Error reading %s: %s", path, err)
+			return
+		}
+
+		values[args[0]] = args[1]
+		if local, _ := cmd.Flags().GetBool("local"); !local {
+			if _, err := config.Decode(path, values); err != nil {
+				result.Fail("Error: %s", err)
+				return
+			}
+		}
+		if err := config.WriteFile(path, values); err != nil {
+			result.Fail("Error writing %s: %s", path, err)
+			return
+		}
+
+		result.Data = configValue{Key: args[0], Value: args[1], File: path}
+		output.Printf("Set %s in %s\n", args[0], path)
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:               "unset <key>",
+	Short:             "Remove a setting (global config unless --local is given)",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeConfigKey,
+	Example:           "mtc config unset workspace_root --global",
+	Run: func(cmd *cobra.Command, args []string) {
+		result := output.Result{Command: "config unset"}
+		defer result.Emit()
+
+		path, err := configScopePath(cmd, true)
+		if err != nil {
+			result.Fail("Error: %s", err)
+			return
+		}
+
+		values, err := config.ReadFile(path)
+		if err != nil {
+			result.Fail("Error reading %s: %s", path, err)
+			return
+		}
+
+		if _, ok := values[args[0]]; !ok {
+			output.Printf("%s is not set in %s\n", args[0], path)
+			return
+		}
+
+		// The global config is only checked when it was valid before, so
+		// unsetting keys one at a time can still repair a broken file
+		local, _ := cmd.Flags().GetBool("local")
+		_, invalid := config.Decode(path, values)
+		delete(values, args[0])
+		if !local && invalid == nil {
+			if _, err := config.Decode(path, values); err != nil {
+				result.Fail("Error: %s", err)
+				return
+			}
+		}
+		if err := config.WriteFile(path, values); err != nil {
+			result.Fail("Error writing %s: %s", path, err)
+			return
+		}
+
+		result.Data = configValue{Key: args[0], File: path}
+		output.Printf("Unset %s in %s\n", args[0], path)
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List settings",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		result := output.Result{Command: "config list"}
+		defer result.Emit()
+
+		path, err := configScopePath(cmd, false)
+		if err != nil {
+			result.Fail("Error: %s", err)
+			return
+		}
+
+		values := map[string]interface{}{}
+		if path == "" {
+			for _, key := range config.Names() {
+				values[key] = viper.Get(key)
+			}
+		} else if values, err = config.ReadFile(path); err != nil {
+			result.Fail("Error reading %s: %s", path, err)
+			return
+		}
+
+		var settings []configValue
+		for _, key := range config.SortedKeys(values) {
+			if values[key] != nil {
+				settings = append(settings, configValue{Key: key, Value: values[key], File: path})
+				output.Printf("%s=%v\n", key, values[key])
+			}
+		}
+		result.Data = settings
+	},
+}
+
+var configExplainCmd = &cobra.Command{
+	Use:   "explain",
+	Short: "Show each effective setting and where its value came from",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		result := output.Result{Command: "config explain"}
+		defer result.Emit()
+
+		globalPath, err := config.GlobalPath(cfgFile)
+		if err != nil {
+			result.Fail("Error: %s", err)
+			return
+		}
+		globalValues, err := config.ReadFile(globalPath)
+		if err != nil {
+			result.Fail("Error reading %s: %s", globalPath, err)
+			return
+		}
+
+		wd, err := projectDir()
+		if err != nil {
+			result.Fail("Error: %s", err)
+			return
+		}
+		localValues, err := lab.ReadConfig(wd)
+		if err != nil {
+			result.Fail("Error reading %s: %s", lab.ConfigFile, err)
+			return
+		}
+
+		profileValues := map[string]interface{}{}
+		profileName, explicitProfile := activeProfile()
+		if profileName != "" {
+			if profile, err := lookupProfile(profileName); err == nil {
+				profileValues = profile.Values()
+			}
+		}
+		profileSource := string(config.SourceProfile) + " " + profileName
+
+		if labRoot != "" {
+			output.Printf("Lab root: %s\n\n", labRoot)
+		}
+
+		var settings []configValue
+		w := tabwriter.NewWriter(output.Human, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+		for _, key := range config.Keys {
+			value := viper.GetString(key.Name)
+			source := ""
+
+			envVar := config.EnvPrefix + "_" + strings.ToUpper(key.Name)
+			if flag := rootCmd.PersistentFlags().Lookup(key.Flag); key.Flag != "" && flag != nil && flag.Changed {
+				source = string(config.SourceFlag) + " --" + key.Flag
+			} else if _, ok := os.LookupEnv(envVar); ok {
+				source = string(config.SourceEnv) + " " + envVar
+			} else if _, ok := profileValues[key.Name]; ok && explicitProfile {
+				source = profileSource
+			} else if _, ok := localValues[key.Name]; ok {
+				source = string(config.SourceLocal) + " " + filepath.Join(wd, lab.ConfigFile)
+			} else if _, ok := profileValues[key.Name]; ok {
+				source = profileSource
+			} else if _, ok := globalValues[key.Name]; ok {
+				source = string(config.SourceGlobal) + " " + globalPath
+			} else if key.Default != "" {
+				source = string(config.SourceDefault)
+			} else {
+				source = "unset"
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\n", key.Name, value, source)
+			settings = append(settings, configValue{Key: key.Name, Value: value, Source: source})
+		}
+		result.Data = map[string]interface{}{"lab_root": labRoot, "settings": settings}
+		w.Flush()
+	},
+}
+
+// configValue is a setting as reported by the config commands in structured
+// output modes
+type configValue struct {
+	Key    string      `json:"key"`
+	Value  interface{} `json:"value,omitempty"`
+	File   string      `json:"file,omitempty"`
+	Source string      `json:"source,omitempty"`
+}
+
+// configScopePath returns the config file selected by --global or --local.
+// Without either flag it returns the global file when a file is required
+// and an empty path (meaning the effective settings) otherwise.
+func configScopePath(cmd *cobra.Command, required bool) (string, error) {
+	global, _ := cmd.Flags().GetBool("global")
+	local, _ := cmd.Flags().GetBool("local")
+
+	switch {
+	case global && local:
+		return "", exitcode.WithCode(exitcode.Usage, fmt.Errorf("--global and --local cannot be used together"))
+	case local:
+		wd, err := projectDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(wd, lab.ConfigFile), nil
+	case global || required:
+		return config.GlobalPath(cfgFile)
+	default:
+		return "", nil
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	for _, c := range []*cobra.Command{configGetCmd, configSetCmd, configUnsetCmd, configListCmd} {
+		c.Flags().Bool("global", false, "Use the global config file")
+		c.Flags().Bool("local", false, "Use the project config file of the current lab")
+		configCmd.AddCommand(c)
+	}
+	configCmd.AddCommand(configExplainCmd)
+}
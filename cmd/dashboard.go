@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"github.com/morethancertified/mtc-cli/internal/exitcode"
+	"github.com/morethancertified/mtc-cli/internal/output"
+	"github.com/morethancertified/mtc-cli/internal/widgets"
+	"github.com/spf13/cobra"
+)
+
+var dashboardCmd = &cobra.Command{
+	Use:   "dashboard [lesson-token]",
+	Short: "Open an interactive dashboard for a lesson",
+	Long: `Open a full-screen dashboard for a lesson showing its tasks and validation
+commands. Commands can be run one at a time or all together with their
+output streamed live, and the lesson can be submitted or reset without
+leaving the dashboard.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeLessonToken,
+	Example:           "mtc dashboard cm4ppz694200blze51ts1234",
+	Run: func(cmd *cobra.Command, args []string) {
+		result := output.Result{Command: "dashboard"}
+		defer result.Emit()
+
+		if output.Structured() {
+			result.FailWith(exitcode.Usage, "Error: the dashboard is interactive and does not support --output %s", output.Format())
+			return
+		}
+
+		if output.Plain() {
+			result.FailWith(exitcode.Usage, "Error: the dashboard needs a full-screen terminal and is not available in plain mode, use mtc status and mtc submit --watch instead")
+			return
+		}
+
+		lessonToken, err := lessonTokenArg(args)
+		if err != nil {
+			result.Fail("Error: %s", err)
+			return
+		}
+		result.LessonToken = lessonToken
+
+		apiClient := newAPIClient()
+		var warnings []error
+		submit := historySubmitter(apiClient, lessonToken, "dashboard", &warnings)
+		err = widgets.RunDashboard(apiClient, lessonToken, submit)
+		for _, warning := range warnings {
+			output.Println("Warning:", warning)
+		}
+		if err != nil {
+			result.Fail("Error running dashboard: %s", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dashboardCmd)
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/morethancertified/mtc-cli/internal/exitcode"
+	"github.com/morethancertified/mtc-cli/internal/history"
+	"github.com/morethancertified/mtc-cli/internal/mtcapi"
+	"github.com/morethancertified/mtc-cli/internal/output"
+	"github.com/morethancertified/mtc-cli/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history [lesson-token]",
+	Short: "List the submissions made for a lesson",
+	Long: `List the submissions made for a lesson from this machine. Every submission's
+command results and graded task statuses are kept so attempts can be compared
+with mtc history diff.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeLessonToken,
+	Example:           "mtc history cm4ppz694200blze51ts1234",
+	Run: func(cmd *cobra.Command, args []string) {
+		result := output.Result{Command: "history"}
+		defer result.Emit()
+
+		lessonToken, err := lessonTokenArg(args)
+		if err != nil {
+			result.Fail("Error: %s", err)
+			return
+		}
+		result.LessonToken = lessonToken
+
+		attempts, err := history.List(lessonToken)
+		if err != nil {
+			result.Fail("Error reading history: %s", err)
+			return
+		}
+		result.Data = attempts
+
+		if output.Structured() {
+			return
+		}
+		if len(attempts) == 0 {
+			output.Println("No submissions recorded for this lesson yet.")
+			return
+		}
+		printAttempts(attempts)
+	},
+}
+
+var historyDiffCmd = &cobra.Command{
+	Use:               "diff <a> <b> [lesson-token]",
+	Short:             "Show what changed between two submissions",
+	Args:              cobra.RangeArgs(2, 3),
+	ValidArgsFunction: completeHistoryDiff,
+	Example:           "mtc history diff 1 2",
+	Run: func(cmd *cobra.Command, args []string) {
+		result := output.Result{Command: "history diff"}
+		defer result.Emit()
+
+		lessonToken, err := lessonTokenArg(args[2:])
+		if err != nil {
+			result.Fail("Error: %s", err)
+			return
+		}
+		result.LessonToken = lessonToken
+
+		var attempts [2]history.Attempt
+		for i, arg := range args[:2] {
+			n, err := strconv.Atoi(arg)
+			if err != nil {
+				result.FailWith(exitcode.Usage, "Error: attempt must be a number, got %q", arg)
+				return
+			}
+			attempts[i], err = history.Get(lessonToken, n)
+			if err != nil {
+				result.Fail("Error: %s", err)
+				return
+			}
+		}
+
+		diff := history.Compare(attempts[0], attempts[1])
+		result.Data = diff
+
+		if !output.Structured() {
+			printAttemptDiff(diff)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.AddCommand(historyDiffCmd)
+}
+
+// submitLesson submits the command results for grading and records the
+// attempt in the local history. A failure to record the attempt is
+// returned as recordErr so callers can warn about it without treating the
+// submission as failed.
+func submitLesson(apiClient *mtcapi.MtcApiClient, lessonToken, source string, results []types.CLICommandResult) (lesson types.Lesson, recordErr, err error) {
+	lesson, err = apiClient.SubmitLesson(lessonToken, results)
+	if err != nil {
+		return lesson, nil, err
+	}
+
+	attempt := history.Attempt{
+		SubmittedAt: time.Now(),
+		LessonToken: lessonToken,
+		CLIVersion:  Version,
+		Source:      source,
+		Results:     results,
+		Tasks:       lesson.Tasks,
+	}
+	for _, result := range results {
+		attempt.Commands = append(attempt.Commands, result.Command)
+	}
+	if err := history.Record(&attempt); err != nil {
+		return lesson, fmt.Errorf("could not save the submission to the history: %w", err), nil
+	}
+
+	return lesson, nil, nil
+}
+
+// historySubmitter returns a widgets submit function for interactive
+// commands. Failures to record the history are collected in warnings since
+// they cannot be printed while the TUI owns the terminal.
+func historySubmitter(apiClient *mtcapi.MtcApiClient, lessonToken, source string, warnings *[]error) func([]types.CLICommandResult) (types.Lesson, error) {
+	return func(results []types.CLICommandResult) (types.Lesson, error) {
+		lesson, recordErr, err := submitLesson(apiClient, lessonToken, source, results)
+		if recordErr != nil {
+			*warnings = append(*warnings, recordErr)
+		}
+		return lesson, err
+	}
+}
+
+func printAttempts(attempts []history.Attempt) {
+	w := tabwriter.NewWriter(output.Human, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tSUBMITTED\tSOURCE\tCOMMANDS PASSING\tTASKS COMPLETE\tCLI VERSION")
+	for _, attempt := range attempts {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d/%d\t%d/%d\t%s\n",
+			attempt.Number,
+			attempt.SubmittedAt.Local().Format(time.RFC1123),
+			attempt.Source,
+			attempt.Passing(), len(attempt.Results),
+			attempt.Completed(), len(attempt.Tasks),
+			attempt.CLIVersion,
+		)
+	}
+	w.Flush()
+}
+
+func printAttemptDiff(diff history.Diff) {
+	if len(diff.Tasks) == 0 && len(diff.Commands) == 0 {
+		output.Printf("Attempts %d and %d have the same results.\n", diff.From, diff.To)
+		return
+	}
+
+	if len(diff.Tasks) > 0 {
+		output.Println("TASKS:")
+		for _, task := range diff.Tasks {
+			from := task.From
+			if from == "" {
+				from = "-"
+			}
+			output.Printf("  %s: %s -> %s\n", task.Title, from, task.To)
+		}
+	}
+
+	for _, command := range diff.Commands {
+		output.Printf("\n$ %s\n", command.Command)
+		output.Printf("  exit code: %s -> %s\n", exitCodeText(command.FromExitCode), exitCodeText(command.ToExitCode))
+		printDiffLines("stdout", command.Stdout)
+		printDiffLines("stderr", command.Stderr)
+	}
+}
+
+func printDiffLines(name string, lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	output.Printf("  %s:\n", name)
+	for _, line := range lines {
+		output.Printf("    %s\n", line)
+	}
+}
+
+// exitCodeText shows a missing exit code as "not run"
+func exitCodeText(code *int) string {
+	if code == nil {
+		return "not run"
+	}
+	return strconv.Itoa(*code)
+}
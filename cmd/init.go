@@ -3,110 +3,414 @@ package cmd
 import (
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
 
-	"github.com/morethancertified/mtc-cli/internal/mtcapi"
+	"github.com/morethancertified/mtc-cli/internal/exitcode"
+	"github.com/morethancertified/mtc-cli/internal/lab"
+	"github.com/morethancertified/mtc-cli/internal/logging"
+	"github.com/morethancertified/mtc-cli/internal/output"
 	"github.com/morethancertified/mtc-cli/internal/types"
+	"github.com/morethancertified/mtc-cli/internal/widgets"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"golang.org/x/term"
 )
 
 var initCmd = &cobra.Command{
-	Use:     "init <lesson-token>",
-	Short:   "Initialize a lab environment",
-	Args:    cobra.ExactArgs(1),
-	Example: "mtc init cm4ppz694200blze51ts1234",
+	Use:               "init <lesson-token>",
+	Short:             "Initialize a lab environment",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeLessonToken,
+	Example:           "mtc init cm4ppz694200blze51ts1234",
 	Run: func(cmd *cobra.Command, args []string) {
+		result := output.Result{Command: "init"}
+		defer result.Emit()
+
 		lessonToken := args[0]
+		result.LessonToken = lessonToken
 		publicOnly, _ := cmd.Flags().GetBool("public-only")
-		
-		apiClient := mtcapi.New(viper.GetString("api_base_url"))
-		
+
+		apiClient := newAPIClient()
+
 		// Get lab info
-		fmt.Println("Fetching lab information...")
+		output.Println("Fetching lab information...")
 		labInfo, err := apiClient.GetLabInfo(lessonToken)
 		if err != nil {
-			fmt.Printf("Error getting lab information: %s\n", err)
+			result.Fail("Error getting lab information: %s", err)
 			return
 		}
-		
-		fmt.Printf("Initializing lab: %s\n", labInfo.Title)
-		
-		// Create lab directory
-		labDir := sanitizeDirectoryName(labInfo.Title)
-		if dirFlag, _ := cmd.Flags().GetString("dir"); dirFlag != "" {
-			labDir = dirFlag
+
+		output.Printf("Initializing lab: %s\n", labInfo.Title)
+
+		// Create lab directory, --dir skips the workspace layout entirely
+		labDir, _ := cmd.Flags().GetString("dir")
+		if labDir == "" {
+			labDir, err = labDirectory(labInfo)
+			if err != nil {
+				result.Fail("Error building lab directory: %s", err)
+				return
+			}
 		}
-		
+
 		// Clean up the directory name
 		labDir = filepath.Clean(labDir)
-		
-		// Create the directory if it doesn't exist
-		if _, err := os.Stat(labDir); os.IsNotExist(err) {
-			if err := os.MkdirAll(labDir, 0755); err != nil {
-				fmt.Printf("Error creating directory %s: %s\n", labDir, err)
-				return
-			}
+		result.Lab = &output.Lab{
+			Title:       labInfo.Title,
+			CourseTitle: labInfo.Course.Title,
+			Directory:   labDir,
 		}
-		
+
 		// Get file listing
-		fmt.Println("Fetching lab files...")
+		output.Println("Fetching lab files...")
 		var files []types.LabFile
-		
+
 		if publicOnly {
 			files, err = apiClient.GetLabPublicFiles(lessonToken)
 		} else {
 			files, err = apiClient.GetLabFiles(lessonToken)
 		}
-		
+
+		if err != nil {
+			result.Fail("Error getting lab files: %s", err)
+			return
+		}
+
+		// Narrow the listing down to what was asked for
+		filter := lab.Filter{}
+		filter.Include, _ = cmd.Flags().GetStringArray("include")
+		filter.Exclude, _ = cmd.Flags().GetStringArray("exclude")
+		filter.Categories, _ = cmd.Flags().GetStringSlice("category")
+		if err := filter.Validate(); err != nil {
+			result.FailWith(exitcode.Usage, "Error: %s", err)
+			return
+		}
+		files = filter.Apply(files)
+
+		if list, _ := cmd.Flags().GetBool("list"); list {
+			for _, file := range files {
+				result.Files = append(result.Files, newOutputFile(file, output.FileListed))
+			}
+			printLabFiles(labDir, files)
+			return
+		}
+
+		// Make sure we are not about to clobber an unrelated directory
+		force, _ := cmd.Flags().GetBool("force")
+		ok, err := checkLabDir(labDir, labInfo, files, force)
 		if err != nil {
-			fmt.Printf("Error getting lab files: %s\n", err)
+			result.Fail("Error checking directory %s: %s", labDir, err)
 			return
 		}
-		
+		if !ok {
+			result.Errors = append(result.Errors, "initialization was not confirmed for "+labDir)
+			return
+		}
+
+		// Create the directory if it doesn't exist
+		if _, err := os.Stat(labDir); os.IsNotExist(err) {
+			if err := os.MkdirAll(labDir, 0755); err != nil {
+				result.Fail("Error creating directory %s: %s", labDir, err)
+				return
+			}
+		}
+
 		// Download files
-		fmt.Printf("Downloading %d files...\n", len(files))
-		
+		output.Printf("Downloading %d files...\n", len(files))
+
 		for i, file := range files {
-			// Determine the target path
-			var filePath string
-			
-			// If it's a public file, extract it to the root of the lab directory
-			if strings.HasPrefix(file.Path, "public/") {
-				// Remove the "public/" prefix
-				targetPath := strings.TrimPrefix(file.Path, "public/")
-				filePath = filepath.Join(labDir, targetPath)
-				fmt.Printf("[%d/%d] Downloading %s to %s...\n", i+1, len(files), file.Path, targetPath)
+			result.Files = append(result.Files, newOutputFile(file, output.FileFailed))
+			fileResult := &result.Files[len(result.Files)-1]
+
+			filePath, targetPath := labFilePath(labDir, file)
+			if targetPath != file.Path {
+				output.Printf("[%d/%d] Downloading %s to %s...\n", i+1, len(files), file.Path, targetPath)
 			} else {
-				// Keep the original path for other files
-				filePath = filepath.Join(labDir, file.Path)
-				fmt.Printf("[%d/%d] Downloading %s...\n", i+1, len(files), file.Path)
+				output.Printf("[%d/%d] Downloading %s...\n", i+1, len(files), file.Path)
 			}
-			
+
 			// Create subdirectories if needed
 			fileDir := filepath.Dir(filePath)
 			if err := os.MkdirAll(fileDir, 0755); err != nil {
-				fmt.Printf("Error creating directory %s: %s\n", fileDir, err)
+				result.Fail("Error creating directory %s: %s", fileDir, err)
+				fileResult.Error = err.Error()
 				continue
 			}
-			
+
+			// Symlinks are recreated rather than downloaded
+			if file.LinkTarget != "" {
+				if err := createSymlink(labDir, filePath, file.LinkTarget); err != nil {
+					result.Fail("Error creating symlink %s: %s", file.Path, err)
+					fileResult.Error = err.Error()
+				} else {
+					fileResult.Status = output.FileLinked
+				}
+				continue
+			}
+
 			// Download file
 			if err := downloadFile(file.URL, filePath); err != nil {
-				fmt.Printf("Error downloading %s: %s\n", file.Path, err)
+				result.Fail("Error downloading %s: %s", file.Path, err)
+				fileResult.Error = err.Error()
 				continue
 			}
+
+			// Apply the file mode so scripts stay executable
+			if err := os.Chmod(filePath, labFileMode(file)); err != nil {
+				result.Fail("Error setting permissions on %s: %s", file.Path, err)
+				fileResult.Error = err.Error()
+				continue
+			}
+
+			fileResult.Status = output.FileDownloaded
+		}
+
+		// Mark the directory as belonging to this lab
+		meta := types.LabMetadata{
+			UserLessonID:  labInfo.UserLessonID,
+			LessonID:      labInfo.LessonID,
+			Title:         labInfo.Title,
+			CourseTitle:   labInfo.Course.Title,
+			InitializedAt: time.Now(),
+		}
+		if err := lab.WriteMetadata(labDir, meta); err != nil {
+			result.Fail("Error writing lab metadata: %s", err)
+			return
+		}
+
+		// Remember the token and platform so lab commands work without arguments
+		err = lab.UpdateConfig(labDir, map[string]interface{}{
+			lab.LessonTokenKey: lessonToken,
+			"api_base_url":     viper.GetString("api_base_url"),
+		})
+		if err != nil {
+			result.Fail("Error writing lab config: %s", err)
+			return
+		}
+
+		// Commit the pristine lab files so git diff shows only the student's work
+		if useGit, _ := cmd.Flags().GetBool("git"); useGit {
+			// Files that could not be written would make git add fail
+			var paths []string
+			for i, file := range files {
+				if status := result.Files[i].Status; status == output.FileDownloaded || status == output.FileLinked {
+					_, targetPath := labFilePath(labDir, file)
+					paths = append(paths, targetPath)
+				}
+			}
+			if err := lab.InitGit(labDir, paths, lab.Gitignore(paths)); err != nil {
+				result.Fail("Error initializing git repository: %s", err)
+			} else {
+				output.Println("Initialized a git repository with the lab files as the first commit.")
+			}
+		}
+
+		// Optionally run the bootstrap scripts the lab ships with
+		scripts := bootstrapScripts(files)
+		if bootstrap, _ := cmd.Flags().GetBool("bootstrap"); bootstrap {
+			yes, _ := cmd.Flags().GetBool("yes")
+			ok, err := runBootstrap(labDir, scripts, yes)
+			if err != nil {
+				result.Fail("Error running bootstrap scripts: %s", err)
+				return
+			}
+			if ok {
+				now := time.Now()
+				meta.BootstrappedAt = &now
+				meta.BootstrapScripts = scripts
+				if err := lab.WriteMetadata(labDir, meta); err != nil {
+					result.Fail("Error writing lab metadata: %s", err)
+					return
+				}
+			}
+		} else if len(scripts) > 0 {
+			output.Printf("\nThis lab ships with %d bootstrap script(s). Run init again with --bootstrap to run them.\n", len(scripts))
 		}
-		
-		// No need to save lab metadata
-		
-		fmt.Printf("\nLab initialized successfully in %s\n", labDir)
-		fmt.Println("You can now cd into the directory and start working on the lab.")
+
+		// Show the lab instructions
+		if noReadme, _ := cmd.Flags().GetBool("no-readme"); !noReadme && !output.Structured() && labInfo.S3Paths.Readme != "" {
+			if readme, err := apiClient.GetLabReadme(labInfo); err != nil {
+				output.Printf("\nCould not fetch the lab README: %s\n", err)
+			} else {
+				printReadme(readme)
+			}
+		}
+
+		output.Printf("\nLab initialized successfully in %s\n", labDir)
+		output.Println("You can now cd into the directory and start working on the lab.")
 	},
 }
 
+// labFilePath returns where a lab file is written on disk along with its path
+// relative to the lab directory
+func labFilePath(labDir string, file types.LabFile) (string, string) {
+	targetPath := lab.TargetPath(file)
+	return filepath.Join(labDir, targetPath), targetPath
+}
+
+// printLabFiles lists the files init would download without fetching them
+func printLabFiles(labDir string, files []types.LabFile) {
+	output.Printf("\n%d file(s) would be downloaded to %s:\n", len(files), labDir)
+	w := tabwriter.NewWriter(output.Human, 0, 0, 2, ' ', 0)
+	for _, file := range files {
+		_, targetPath := labFilePath(labDir, file)
+		fmt.Fprintf(w, "%s\t%s\t%d bytes\n", targetPath, lab.Category(file), file.Size)
+	}
+	w.Flush()
+}
+
+// newOutputFile returns the Result representation of a lab file
+func newOutputFile(file types.LabFile, status string) output.File {
+	return output.File{
+		Path:     file.Path,
+		Target:   lab.TargetPath(file),
+		Category: lab.Category(file),
+		Size:     file.Size,
+		Status:   status,
+	}
+}
+
+// checkLabDir refuses to initialize into a non-empty directory that is not
+// already this lab, unless forced or the user confirms the overwrite. It
+// returns false when initialization should not continue.
+func checkLabDir(labDir string, labInfo types.LabInfo, files []types.LabFile, force bool) (bool, error) {
+	entries, err := os.ReadDir(labDir)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if len(entries) == 0 || force {
+		return true, nil
+	}
+
+	meta, found, err := lab.ReadMetadata(labDir)
+	if err != nil {
+		return false, fmt.Errorf("reading lab metadata: %w", err)
+	}
+	if found && meta.UserLessonID == labInfo.UserLessonID {
+		return true, nil
+	}
+
+	if found {
+		output.Printf("\n%s already contains a different lab: %s\n", labDir, meta.Title)
+	} else {
+		output.Printf("\n%s is not empty and was not created by mtc init.\n", labDir)
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		output.Println("Refusing to initialize here, use --force to override.")
+		return false, nil
+	}
+
+	var overwritten []string
+	for _, file := range files {
+		filePath, targetPath := labFilePath(labDir, file)
+		if _, err := os.Lstat(filePath); err == nil {
+			overwritten = append(overwritten, targetPath)
+		}
+	}
+
+	if len(overwritten) == 0 {
+		output.Println("No existing files will be overwritten.")
+	} else {
+		output.Println("The following files will be overwritten:")
+		output.Println("------------------------------------------------------------------")
+		for _, path := range overwritten {
+			output.Println(path)
+		}
+		output.Println("------------------------------------------------------------------")
+	}
+
+	ok, err := widgets.Confirm("Initialize the lab here anyway?", false)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		output.Println("Aborting...")
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// bootstrapScripts returns the paths of the executable bootstrap files in
+// the listing, in the order they should run
+func bootstrapScripts(files []types.LabFile) []string {
+	var scripts []string
+	for _, file := range files {
+		if file.LinkTarget != "" || lab.Category(file) != lab.CategoryBootstrap {
+			continue
+		}
+		if labFileMode(file)&0111 != 0 {
+			scripts = append(scripts, file.Path)
+		}
+	}
+	sort.Strings(scripts)
+	return scripts
+}
+
+// runBootstrap shows the bootstrap scripts to the user and, once confirmed
+// (or straight away when yes is set), runs them one after the other in the
+// lab directory. It returns false when there was nothing to run or the user
+// declined.
+func runBootstrap(labDir string, scripts []string, yes bool) (bool, error) {
+	if len(scripts) == 0 {
+		output.Println("\nThis lab has no bootstrap scripts to run.")
+		return false, nil
+	}
+
+	output.Println("\nThe following bootstrap script(s) will be run in", labDir)
+	for _, script := range scripts {
+		b, err := os.ReadFile(filepath.Join(labDir, script))
+		if err != nil {
+			return false, err
+		}
+		output.Println("------------------------------------------------------------------")
+		output.Println(script)
+		output.Println("------------------------------------------------------------------")
+		output.Println(strings.TrimRight(string(b), "\n"))
+	}
+	output.Println("------------------------------------------------------------------")
+
+	if !yes {
+		ready, err := widgets.Confirm("Run the bootstrap scripts?", true)
+		if err != nil {
+			return false, err
+		}
+		if !ready {
+			output.Println("Skipping bootstrap.")
+			return false, nil
+		}
+	}
+
+	for _, script := range scripts {
+		output.Printf("\n==> %s\n", script)
+
+		c := exec.Command("./" + script)
+		c.Dir = labDir
+		c.Stdin = os.Stdin
+		c.Stdout = output.Human
+		c.Stderr = os.Stderr
+		if err := c.Run(); err != nil {
+			return false, fmt.Errorf("%s: %w", script, err)
+		}
+	}
+
+	output.Println("\nBootstrap complete!")
+	return true, nil
+}
+
 func downloadFile(url, filePath string) error {
 	// Create the file
 	out, err := os.Create(filePath)
@@ -114,29 +418,150 @@ func downloadFile(url, filePath string) error {
 		return err
 	}
 	defer out.Close()
-	
+
 	// Get the data
+	slog.Debug("downloading file", "url", logging.RedactURL(url), "path", filePath)
+	start := time.Now()
 	resp, err := http.Get(url)
 	if err != nil {
+		slog.Warn("download failed", "path", filePath, "error", err)
 		return err
 	}
 	defer resp.Body.Close()
-	
+
 	// Check server response
 	if resp.StatusCode != http.StatusOK {
+		slog.Warn("download failed", "path", filePath, "status", resp.StatusCode)
 		return fmt.Errorf("bad status: %s", resp.Status)
 	}
-	
+
 	// Writer the body to file
-	_, err = io.Copy(out, resp.Body)
-	return err
+	n, err := io.Copy(out, resp.Body)
+	if err != nil {
+		return err
+	}
+	slog.Info("downloaded file", "path", filePath, "bytes", n, "duration", time.Since(start))
+	return nil
+}
+
+// labFileMode returns the permissions to apply to a downloaded lab file.
+// When the server sends no mode, shell scripts under bootstrap/ are made
+// executable and everything else is a regular 0644 file.
+func labFileMode(file types.LabFile) os.FileMode {
+	if file.Mode != "" {
+		if mode, err := strconv.ParseUint(file.Mode, 8, 32); err == nil {
+			return os.FileMode(mode).Perm()
+		}
+	}
+
+	if lab.Category(file) == lab.CategoryBootstrap && strings.HasSuffix(file.Path, ".sh") {
+		return 0755
+	}
+
+	return 0644
+}
+
+// createSymlink creates a symlink at linkPath pointing to target, refusing
+// targets that would resolve outside of the lab directory
+func createSymlink(labDir, linkPath, target string) error {
+	if filepath.IsAbs(target) {
+		return fmt.Errorf("absolute link target %q is not allowed", target)
+	}
+
+	resolved := filepath.Join(filepath.Dir(linkPath), target)
+	rel, err := filepath.Rel(labDir, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("link target %q points outside the lab directory", target)
+	}
+
+	// Replace whatever is already there so re-running init is idempotent
+	if _, err := os.Lstat(linkPath); err == nil {
+		if err := os.Remove(linkPath); err != nil {
+			return err
+		}
+	}
+
+	return os.Symlink(target, linkPath)
+}
+
+// labDirectory builds the lab directory from the configured workspace root
+// and layout template. The lab's fields are sanitized before the template
+// runs, so only slashes in the template itself separate directories, and
+// every resulting path segment is sanitized again on its own.
+func labDirectory(labInfo types.LabInfo) (string, error) {
+	tmpl, err := template.New("lab_dir_template").Option("missingkey=error").Parse(viper.GetString("lab_dir_template"))
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, sanitizeLabInfo(labInfo)); err != nil {
+		return "", err
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(b.String(), "/") {
+		segment = strings.TrimSpace(segment)
+		if segment == "" || segment == "." || segment == ".." {
+			continue
+		}
+		segments = append(segments, sanitizeDirectoryName(segment))
+	}
+	if len(segments) == 0 {
+		segments = append(segments, sanitizeDirectoryName(labInfo.Title))
+	}
+
+	root, err := workspaceRoot()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(append([]string{root}, segments...)...), nil
+}
+
+// sanitizeLabInfo returns a copy of labInfo with every field the layout
+// template can use made safe as a single directory name. Empty fields stay
+// empty so the segments they make up are dropped.
+func sanitizeLabInfo(labInfo types.LabInfo) types.LabInfo {
+	for _, field := range []*string{
+		&labInfo.UserLessonID,
+		&labInfo.LessonID,
+		&labInfo.Title,
+		&labInfo.Course.ID,
+		&labInfo.Course.Title,
+		&labInfo.User.ID,
+		&labInfo.User.Email,
+		&labInfo.S3Paths.Base,
+		&labInfo.S3Paths.Public,
+		&labInfo.S3Paths.Bootstrap,
+		&labInfo.S3Paths.Readme,
+	} {
+		if *field != "" {
+			*field = sanitizeDirectoryName(*field)
+		}
+	}
+	return labInfo
+}
+
+// workspaceRoot returns the configured workspace root with a leading ~
+// expanded, empty when labs go into the current directory
+func workspaceRoot() (string, error) {
+	root := viper.GetString("workspace_root")
+	if root == "~" || strings.HasPrefix(root, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		root = filepath.Join(home, strings.TrimPrefix(root, "~"))
+	}
+	return root, nil
 }
 
 // sanitizeDirectoryName cleans up a string to be used as a directory name
 func sanitizeDirectoryName(name string) string {
 	// Remove quotes
 	name = strings.Trim(name, "\"'`")
-	
+
 	// Replace problematic characters with underscores
 	replacer := strings.NewReplacer(
 		"/", "_",
@@ -150,23 +575,32 @@ func sanitizeDirectoryName(name string) string {
 		"|", "_",
 		" ", "_", // Replace spaces with underscores
 	)
-	
+
 	// Ensure the name doesn't have any remaining problematic characters
 	sanitized := replacer.Replace(name)
-	
+
 	// Convert to lowercase
 	sanitized = strings.ToLower(sanitized)
-	
-	// If the name is empty after sanitization, use a default name
-	if sanitized == "" {
+
+	// If the name is empty or only dots after sanitization, use a default name
+	if strings.Trim(sanitized, ".") == "" {
 		return "lab"
 	}
-	
+
 	return sanitized
 }
 
 func init() {
 	rootCmd.AddCommand(initCmd)
 	initCmd.Flags().BoolP("public-only", "p", false, "Download only public files")
-	initCmd.Flags().StringP("dir", "d", "", "Directory to initialize the lab in (defaults to lab title)")
+	initCmd.Flags().StringP("dir", "d", "", "Directory to initialize the lab in (defaults to the lab layout template)")
+	initCmd.Flags().BoolP("force", "f", false, "Initialize even if the directory is not empty")
+	initCmd.Flags().BoolP("bootstrap", "b", false, "Run the lab's bootstrap scripts after downloading")
+	initCmd.Flags().BoolP("yes", "y", false, "Run the bootstrap scripts without asking for confirmation")
+	initCmd.Flags().Bool("git", false, "Initialize a git repository and commit the downloaded files")
+	initCmd.Flags().Bool("no-readme", false, "Do not show the lab README after initializing")
+	initCmd.Flags().StringArray("include", nil, "Only download files matching this glob pattern (repeatable, supports **)")
+	initCmd.Flags().StringArray("exclude", nil, "Skip files matching this glob pattern (repeatable, supports **)")
+	initCmd.Flags().StringSlice("category", nil, "Only download files in these categories (public, bootstrap, other)")
+	initCmd.Flags().Bool("list", false, "List the files that would be downloaded without downloading them")
 }
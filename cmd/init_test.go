@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/morethancertified/mtc-cli/internal/types"
+	"github.com/spf13/viper"
+)
+
+func TestLabDirectory(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("workspace_root", "/labs")
+
+	tests := []struct {
+		name     string
+		template string
+		title    string
+		course   string
+		want     string
+	}{
+		{"title", "{{.Title}}", "Intro to Terraform", "", "/labs/intro_to_terraform"},
+		{"slash in title", "{{.Title}}", "CI/CD Pipelines", "", "/labs/ci_cd_pipelines"},
+		{"course and title", "{{.Course.Title}}/{{.Title}}", "CI/CD Pipelines", "DevOps: Zero/Hero", "/labs/devops__zero_hero/ci_cd_pipelines"},
+		{"dot dot title", "{{.Title}}", "..", "", "/labs/lab"},
+		{"escaping title", "{{.Title}}", "../../etc", "", "/labs/.._.._etc"},
+		{"escaping course", "{{.Course.Title}}/{{.Title}}", "Lab", "..", "/labs/lab/lab"},
+		{"empty course", "{{.Course.Title}}/{{.Title}}", "Lab", "", "/labs/lab"},
+		{"dot dot in template", "../{{.Title}}", "Lab", "", "/labs/lab"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Set("lab_dir_template", tt.template)
+			info := types.LabInfo{Title: tt.title}
+			info.Course.Title = tt.course
+
+			got, err := labDirectory(info)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != filepath.FromSlash(tt.want) {
+				t.Errorf("labDirectory(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateSymlink(t *testing.T) {
+	tests := []struct {
+		name    string
+		link    string
+		target  string
+		wantErr string
+	}{
+		{"sibling", "current", "v2", ""},
+		{"into subdirectory", "main.tf", "modules/root/main.tf", ""},
+		{"nested up within the lab", "modules/network/shared.tf", "../../shared.tf", ""},
+		{"dot", "self", ".", ""},
+		{"absolute", "passwd", "/etc/passwd", "absolute link target"},
+		{"parent", "up", "..", "outside the lab directory"},
+		{"escape", "passwd", "../etc/passwd", "outside the lab directory"},
+		{"nested escape", "modules/network/passwd", "../../../etc/passwd", "outside the lab directory"},
+		{"escape through a subdirectory", "link", "modules/../../outside", "outside the lab directory"},
+		{"sibling with lab prefix", "link", "../lab-other/file", "outside the lab directory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labDir := filepath.Join(t.TempDir(), "lab")
+			linkPath := filepath.Join(labDir, filepath.FromSlash(tt.link))
+			if err := os.MkdirAll(filepath.Dir(linkPath), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			err := createSymlink(labDir, linkPath, tt.target)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("createSymlink(%q) error = %v, want %q", tt.target, err, tt.wantErr)
+				}
+				if _, err := os.Lstat(linkPath); err == nil {
+					t.Error("a refused symlink was created")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, err := os.Readlink(linkPath); err != nil || got != tt.target {
+				t.Errorf("link points to %q (%v), want %q", got, err, tt.target)
+			}
+		})
+	}
+}
+
+func TestCreateSymlinkReplaces(t *testing.T) {
+	labDir := t.TempDir()
+	linkPath := filepath.Join(labDir, "current")
+	if err := os.WriteFile(linkPath, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, target := range []string{"v1", "v2"} {
+		if err := createSymlink(labDir, linkPath, target); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, _ := os.Readlink(linkPath); got != "v2" {
+		t.Errorf("link points to %q, want v2", got)
+	}
+}
+
+func TestLabFileMode(t *testing.T) {
+	tests := []struct {
+		name string
+		file types.LabFile
+		want os.FileMode
+	}{
+		{"server mode", types.LabFile{Path: "public/run.py", Mode: "0755"}, 0755},
+		{"server mode without leading zero", types.LabFile{Path: "public/key", Mode: "600"}, 0600},
+		{"server mode wins over fallback", types.LabFile{Path: "bootstrap/setup.sh", Mode: "0644"}, 0644},
+		{"special bits dropped", types.LabFile{Path: "public/tool", Mode: "4755"}, 0755},
+		{"invalid server mode", types.LabFile{Path: "public/main.tf", Mode: "rwx"}, 0644},
+		{"bootstrap script", types.LabFile{Path: "bootstrap/setup.sh"}, 0755},
+		{"nested bootstrap script", types.LabFile{Path: "bootstrap/steps/01-install.sh"}, 0755},
+		{"bootstrap data", types.LabFile{Path: "bootstrap/values.yaml"}, 0644},
+		{"public script", types.LabFile{Path: "public/setup.sh"}, 0644},
+		{"regular file", types.LabFile{Path: "public/main.tf"}, 0644},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := labFileMode(tt.file); got != tt.want {
+				t.Errorf("labFileMode(%+v) = %o, want %o", tt.file, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var labCmd = &cobra.Command{
+	Use:   "lab",
+	Short: "Work with the lab for a lesson",
+}
+
+func init() {
+	rootCmd.AddCommand(labCmd)
+}
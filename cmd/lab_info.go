@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/morethancertified/mtc-cli/internal/lab"
+	"github.com/morethancertified/mtc-cli/internal/output"
+	"github.com/morethancertified/mtc-cli/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// labInfoOutput is the data lab info reports in structured output modes
+type labInfoOutput struct {
+	types.LabInfo
+	FileCounts map[string]int `json:"file_counts"`
+}
+
+var labInfoCmd = &cobra.Command{
+	Use:               "info [lesson-token]",
+	Short:             "Show the lab metadata for a lesson",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeLessonToken,
+	Example:           "mtc lab info cm4ppz694200blze51ts1234 --output json",
+	Run: func(cmd *cobra.Command, args []string) {
+		result := output.Result{Command: "lab info"}
+		defer result.Emit()
+
+		lessonToken, err := lessonTokenArg(args)
+		if err != nil {
+			result.Fail("Error: %s", err)
+			return
+		}
+		result.LessonToken = lessonToken
+
+		apiClient := newAPIClient()
+		labInfo, err := apiClient.GetLabInfo(lessonToken)
+		if err != nil {
+			result.Fail("Error getting lab information: %s", err)
+			return
+		}
+
+		files, err := apiClient.GetLabFiles(lessonToken)
+		if err != nil {
+			result.Fail("Error getting lab files: %s", err)
+			return
+		}
+
+		info := labInfoOutput{
+			LabInfo:    labInfo,
+			FileCounts: lab.CountByCategory(files),
+		}
+		result.Data = info
+
+		if !output.Structured() {
+			printLabInfo(info)
+		}
+	},
+}
+
+func printLabInfo(info labInfoOutput) {
+	var counts []string
+	for _, category := range lab.Categories {
+		counts = append(counts, fmt.Sprintf("%d %s", info.FileCounts[category], category))
+	}
+
+	w := tabwriter.NewWriter(output.Human, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Lab:\t%s\n", info.Title)
+	fmt.Fprintf(w, "Lesson token:\t%s\n", info.UserLessonID)
+	fmt.Fprintf(w, "Lesson ID:\t%s\n", info.LessonID)
+	fmt.Fprintf(w, "Course:\t%s (%s)\n", info.Course.Title, info.Course.ID)
+	fmt.Fprintf(w, "User:\t%s (%s)\n", info.User.Email, info.User.ID)
+	fmt.Fprintf(w, "Created:\t%s\n", info.CreatedAt.Local().Format(time.RFC1123))
+	fmt.Fprintf(w, "Updated:\t%s\n", info.UpdatedAt.Local().Format(time.RFC1123))
+	fmt.Fprintf(w, "Files:\t%s\n", strings.Join(counts, ", "))
+	fmt.Fprintf(w, "S3 base:\t%s\n", info.S3Paths.Base)
+	fmt.Fprintf(w, "S3 public:\t%s\n", info.S3Paths.Public)
+	fmt.Fprintf(w, "S3 bootstrap:\t%s\n", info.S3Paths.Bootstrap)
+	fmt.Fprintf(w, "S3 README:\t%s\n", info.S3Paths.Readme)
+	w.Flush()
+}
+
+func init() {
+	labCmd.AddCommand(labInfoCmd)
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/morethancertified/mtc-cli/internal/output"
+	"github.com/morethancertified/mtc-cli/internal/widgets"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var labReadmeCmd = &cobra.Command{
+	Use:               "readme [lesson-token]",
+	Short:             "Show the lab README",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeLessonToken,
+	Example:           "mtc lab readme cm4ppz694200blze51ts1234",
+	Run: func(cmd *cobra.Command, args []string) {
+		result := output.Result{Command: "lab readme"}
+		defer result.Emit()
+
+		lessonToken, err := lessonTokenArg(args)
+		if err != nil {
+			result.Fail("Error: %s", err)
+			return
+		}
+		result.LessonToken = lessonToken
+
+		apiClient := newAPIClient()
+		labInfo, err := apiClient.GetLabInfo(lessonToken)
+		if err != nil {
+			result.Fail("Error getting lab information: %s", err)
+			return
+		}
+
+		readme, err := apiClient.GetLabReadme(labInfo)
+		if err != nil {
+			result.Fail("Error getting lab README: %s", err)
+			return
+		}
+		result.Data = map[string]string{"readme": readme}
+
+		printReadme(readme)
+	},
+}
+
+// printReadme renders the README when writing to a terminal and prints the
+// raw markdown in plain mode. Nothing is printed in structured output modes.
+func printReadme(readme string) {
+	if output.Structured() {
+		return
+	}
+
+	fd := int(os.Stdout.Fd())
+	if output.Plain() {
+		output.Println(readme)
+		return
+	}
+
+	width := 80
+	if w, _, err := term.GetSize(fd); err == nil && w < width {
+		width = w
+	}
+
+	output.Println()
+	output.Println(widgets.RenderMarkdown(readme, width))
+	output.Println()
+}
+
+func init() {
+	labCmd.AddCommand(labReadmeCmd)
+}
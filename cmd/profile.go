@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/morethancertified/mtc-cli/internal/config"
+	"github.com/morethancertified/mtc-cli/internal/exitcode"
+	"github.com/morethancertified/mtc-cli/internal/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage named profiles for platforms and accounts",
+	Long: `Manage named profiles for platforms and accounts.
+
+A profile bundles an API base URL, an optional API key and default settings.
+Select one for a single run with --profile or MTC_PROFILE, or make it the
+default with mtc profile use.`,
+}
+
+var profileAddCmd = &cobra.Command{
+	Use:     "add <name> <api-base-url>",
+	Short:   "Add or replace a profile",
+	Args:    cobra.ExactArgs(2),
+	Example: "mtc profile add local http://localhost:3000/api/v1",
+	Run: func(cmd *cobra.Command, args []string) {
+		result := output.Result{Command: "profile add"}
+		defer result.Emit()
+
+		name := args[0]
+		if err := config.ValidateProfileName(name); err != nil {
+			result.FailWith(exitcode.Usage, "Error: %s", err)
+			return
+		}
+
+		profile := config.Profile{APIBaseURL: args[1]}
+		profile.APIKey, _ = cmd.Flags().GetString("api-key")
+		profile.WorkspaceRoot, _ = cmd.Flags().GetString("workspace-root")
+		profile.LabDirTemplate, _ = cmd.Flags().GetString("lab-dir-template")
+
+		err := updateProfiles(func(profiles map[string]config.Profile, values map[string]interface{}) error {
+			profiles[name] = profile
+			return nil
+		})
+		if err != nil {
+			result.Fail("Error saving profile: %s", err)
+			return
+		}
+
+		result.Data = map[string]string{"profile": name}
+		output.Printf("Profile %s saved\n", name)
+	},
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		result := output.Result{Command: "profile list"}
+		defer result.Emit()
+
+		profiles, _, err := readProfiles()
+		if err != nil {
+			result.Fail("Error reading profiles: %s", err)
+			return
+		}
+
+		if len(profiles) == 0 {
+			output.Println("No profiles configured. Add one with mtc profile add.")
+			return
+		}
+
+		current := viper.GetString("profile")
+		var listed []profileListing
+		w := tabwriter.NewWriter(output.Human, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "\tNAME\tAPI BASE URL\tAPI KEY")
+		for _, name := range config.ProfileNames(profiles) {
+			marker := ""
+			if name == current {
+				marker = "*"
+			}
+			apiKey := ""
+			if profiles[name].APIKey != "" {
+				apiKey = "set"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", marker, name, profiles[name].APIBaseURL, apiKey)
+			listed = append(listed, profileListing{
+				Name:       name,
+				APIBaseURL: profiles[name].APIBaseURL,
+				APIKeySet:  apiKey != "",
+				Current:    name == current,
+			})
+		}
+		result.Data = listed
+		w.Flush()
+	},
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:               "use <name>",
+	Short:             "Make a profile the default",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProfileNames,
+	Example:           "mtc profile use local",
+	Run: func(cmd *cobra.Command, args []string) {
+		result := output.Result{Command: "profile use"}
+		defer result.Emit()
+
+		name := args[0]
+		err := updateProfiles(func(profiles map[string]config.Profile, values map[string]interface{}) error {
+			values["profile"] = name
+			return nil
+		})
+		if err != nil {
+			result.Fail("Error saving profile: %s", err)
+			return
+		}
+
+		result.Data = map[string]string{"profile": name}
+		output.Printf("Now using profile %s\n", name)
+	},
+}
+
+var profileRemoveCmd = &cobra.Command{
+	Use:               "remove <name>",
+	Aliases:           []string{"rm"},
+	Short:             "Remove a profile",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProfileNames,
+	Example:           "mtc profile remove local",
+	Run: func(cmd *cobra.Command, args []string) {
+		result := output.Result{Command: "profile remove"}
+		defer result.Emit()
+
+		name := args[0]
+		err := updateProfiles(func(profiles map[string]config.Profile, values map[string]interface{}) error {
+			if _, ok := profiles[name]; !ok {
+				return fmt.Errorf("profile %q does not exist", name)
+			}
+			delete(profiles, name)
+			if values["profile"] == name {
+				delete(values, "profile")
+			}
+			return nil
+		})
+		if err != nil {
+			result.Fail("Error removing profile: %s", err)
+			return
+		}
+
+		result.Data = map[string]string{"profile": name}
+		output.Printf("Profile %s removed\n", name)
+	},
+}
+
+// profileListing is a profile as reported by profile list in structured
+// output modes; the API key itself is never printed
+type profileListing struct {
+	Name       string `json:"name"`
+	APIBaseURL string `json:"api_base_url"`
+	APIKeySet  bool   `json:"api_key_set"`
+	Current    bool   `json:"current"`
+}
+
+// readProfiles reads the profiles and raw values of the global config file
+func readProfiles() (map[string]config.Profile, map[string]interface{}, error) {
+	globalPath, err := config.GlobalPath(cfgFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values, err := config.ReadFile(globalPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	profiles, err := config.Profiles(values)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return profiles, values, nil
+}
+
+// updateProfiles applies fn to the profiles in the global config file and
+// writes the result back. Selecting an unknown profile is rejected.
+func updateProfiles(fn func(profiles map[string]config.Profile, values map[string]interface{}) error) error {
+	profiles, values, err := readProfiles()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(profiles, values); err != nil {
+		return err
+	}
+
+	if name, ok := values["profile"].(string); ok {
+		if _, exists := profiles[name]; !exists {
+			return fmt.Errorf("profile %q does not exist", name)
+		}
+	}
+
+	config.SetProfiles(values, profiles)
+
+	globalPath, err := config.GlobalPath(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	if _, err := config.Decode(globalPath, values); err != nil {
+		return err
+	}
+	return config.WriteFile(globalPath, values)
+}
+
+// knownPlatforms returns the platforms a lab can belong to: the built-in
+// ones followed by the user's profiles, each with the API key to use for it
+func knownPlatforms() []config.Platform {
+	platforms := append([]config.Platform{}, config.Platforms...)
+	for i := range platforms {
+		if platforms[i].APIBaseURL == viper.GetString("api_base_url") {
+			platforms[i].APIKey = viper.GetString("api_key")
+		}
+	}
+
+	if profiles, _, err := readProfiles(); err == nil {
+		for _, name := range config.ProfileNames(profiles) {
+			platforms = append(platforms, config.Platform{
+				Name:       "Profile " + name,
+				APIBaseURL: profiles[name].APIBaseURL,
+				APIKey:     profiles[name].APIKey,
+			})
+		}
+	}
+
+	return platforms
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileAddCmd, profileListCmd, profileUseCmd, profileRemoveCmd)
+	profileAddCmd.Flags().String("api-key", "", "API key to send with every request")
+	profileAddCmd.Flags().String("workspace-root", "", "Workspace root for labs initialized with this profile")
+	profileAddCmd.Flags().String("lab-dir-template", "", "Lab directory template for this profile")
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/morethancertified/mtc-cli/internal/exitcode"
+	"github.com/morethancertified/mtc-cli/internal/output"
+	"github.com/morethancertified/mtc-cli/internal/report"
+	"github.com/morethancertified/mtc-cli/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// addReportFlag adds the repeatable --report flag to a command
+func addReportFlag(cmd *cobra.Command) {
+	cmd.Flags().StringArray("report", nil, "Write a report as format=path, format is junit, markdown or github (path defaults to $GITHUB_STEP_SUMMARY), repeatable")
+}
+
+// reportSpecs parses the --report flags of cmd
+func reportSpecs(cmd *cobra.Command) ([]report.Spec, error) {
+	values, _ := cmd.Flags().GetStringArray("report")
+
+	var specs []report.Spec
+	for _, value := range values {
+		spec, err := report.ParseSpec(value)
+		if err != nil {
+			return nil, exitcode.WithCode(exitcode.Usage, err)
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// writeReports writes the graded lesson to every requested report
+func writeReports(specs []report.Spec, result *output.Result, lesson types.Lesson, results []types.CLICommandResult) {
+	r := report.Report{
+		LessonToken: result.LessonToken,
+		Lesson:      lesson,
+		Results:     results,
+		GeneratedAt: time.Now(),
+	}
+
+	for _, spec := range specs {
+		if err := report.Write(spec, r); err != nil {
+			result.Fail("Error writing %s report %s: %s", spec.Format, spec.Path, err)
+			continue
+		}
+		output.Printf("Wrote %s report to %s\n", spec.Format, spec.Path)
+	}
+}
@@ -1,17 +1,43 @@
 package cmd
 
 import (
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 
+	"github.com/morethancertified/mtc-cli/internal/config"
+	"github.com/morethancertified/mtc-cli/internal/exitcode"
+	"github.com/morethancertified/mtc-cli/internal/lab"
+	"github.com/morethancertified/mtc-cli/internal/logging"
+	"github.com/morethancertified/mtc-cli/internal/mtcapi"
+	"github.com/morethancertified/mtc-cli/internal/output"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var cfgFile string
 
+var outputFormat string
+
+var plainOutput bool
+
+// Logging flags and the log file opened for them
+var (
+	verbosity int
+	quiet     bool
+	logFormat string
+	logFile   string
+	logCloser io.Closer
+)
+
 var Version = "v0.0.0"
 
+// labRoot is the directory holding the nearest project config (.mtc.json)
+// above the working directory, empty when there is none
+var labRoot string
+
 var rootCmd = &cobra.Command{
 	Use:     "mtc-cli",
 	Short:   "The MoreThanCertified CLI",
@@ -20,56 +46,242 @@ var rootCmd = &cobra.Command{
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
-	rootCmd.CompletionOptions.HiddenDefaultCmd = true
+	for _, key := range config.Keys {
+		if key.Default != "" {
+			viper.SetDefault(key.Name, key.Default)
+		}
+	}
+
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		err := initConfig(cmd)
+		if err == nil {
+			return nil
+		}
+		if _, ok := exitcode.Attached(err); !ok {
+			err = exitcode.WithCode(exitcode.ForError(err), err)
+		}
+		if exitcode.ForError(err) != exitcode.Usage {
+			// Usage is no help with a broken config file
+			cmd.SilenceUsage = true
+		}
+		return err
+	}
 	rootCmd.SetHelpCommand(&cobra.Command{Hidden: true})
-	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "config file (default is $HOME/.config/mtc/mtc.yaml)")
+	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "config file, .json, .yaml or .toml (default is $HOME/.config/mtc/config.json)")
 	rootCmd.PersistentFlags().StringP("api-base-url", "l", viper.GetString("api_base_url"), "API base URL")
+	rootCmd.PersistentFlags().String("profile", "", "Named profile to use (see mtc profile)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", output.Text, "Output format: text, json or yaml")
+	rootCmd.PersistentFlags().BoolVar(&plainOutput, "plain", false, "Plain output: ASCII markers, no colours or animations and line-based prompts")
+	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "Log more, -v for info and -vv for debug messages")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Only log errors")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logging.Text, "Log format: text or json")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Append logs to this file instead of stderr")
+	rootCmd.MarkFlagsMutuallyExclusive("verbose", "quiet")
 	viper.BindPFlag("api_base_url", rootCmd.PersistentFlags().Lookup("api-base-url"))
+	viper.BindPFlag("profile", rootCmd.PersistentFlags().Lookup("profile"))
+	rootCmd.RegisterFlagCompletionFunc("profile", completeProfileNames)
+	rootCmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(output.Formats, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.RegisterFlagCompletionFunc("log-format", cobra.FixedCompletions([]string{logging.Text, logging.JSON}, cobra.ShellCompDirectiveNoFileComp))
 }
 
-func initConfig() {
-	if cfgFile != "" {
-		viper.SetConfigFile(cfgFile)
-		err := viper.ReadInConfig()
-		cobra.CheckErr(err)
-	} else {
-		home, err := os.UserHomeDir()
-		cobra.CheckErr(err)
-
-		configDir := filepath.Join(home, ".config", "mtc")
-		err = os.MkdirAll(configDir, os.ModePerm)
-		cobra.CheckErr(err)
-
-		viper.SetConfigName("config")
-		viper.SetConfigType("json")
-		viper.AddConfigPath(configDir)
-		viper.AutomaticEnv()
-		viper.SetEnvPrefix("MTC")
-
-		if err := viper.ReadInConfig(); err != nil {
-			if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-				viper.Set("api_base_url", "https://app.morethancertified.com/api/v1")
-				err := viper.SafeWriteConfigAs(filepath.Join(configDir, "config.json"))
-				cobra.CheckErr(err)
-			} else {
-				cobra.CheckErr(err)
+// initConfig sets up output and logging and loads the global, profile and
+// project config for cmd. Errors carry the exit code to use: Usage for bad
+// global flags and Config for config files that cannot be loaded.
+func initConfig(cmd *cobra.Command) error {
+	if err := output.SetFormat(outputFormat); err != nil {
+		return exitcode.WithCode(exitcode.Usage, err)
+	}
+	output.SetPlain(plainOutput)
+
+	closer, err := logging.Setup(logging.Level(verbosity, quiet), logFormat, logFile)
+	if err != nil {
+		return exitcode.WithCode(exitcode.Usage, err)
+	}
+	logCloser = closer
+	slog.Debug("starting", "version", Version, "args", os.Args[1:])
+
+	viper.AutomaticEnv()
+	viper.SetEnvPrefix(config.EnvPrefix)
+
+	globalPath, err := config.GlobalPath(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(globalPath); os.IsNotExist(err) && cfgFile == "" {
+		// Only persist the API URL, the other settings fall back to their defaults
+		apiBaseURL, _ := config.Lookup("api_base_url")
+		err := config.WriteFile(globalPath, map[string]interface{}{
+			config.VersionKey: config.CurrentVersion,
+			"api_base_url":    apiBaseURL.Default,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// check attaches the Config exit code to errors loading a config file.
+	// Commands that repair the config, and shell completion, have to keep
+	// working when it is invalid, so for them problems are only warnings.
+	lenient := repairsConfig(cmd)
+	check := func(err error) error {
+		if err == nil {
+			return nil
+		}
+		if lenient {
+			slog.Warn("config is invalid", "path", globalPath, "error", err)
+			return nil
+		}
+		return exitcode.WithCode(exitcode.Config, err)
+	}
+
+	// Bring older config files up to date before anything reads them
+	backup, err := config.Migrate(globalPath)
+	if backup != "" {
+		slog.Warn("migrated config to the current schema version", "path", globalPath, "version", config.CurrentVersion, "backup", backup)
+	}
+	if err == nil {
+		_, err = config.Load(globalPath)
+	}
+	if err := check(err); err != nil {
+		return err
+	}
+
+	viper.SetConfigFile(globalPath)
+	if err := check(viper.ReadInConfig()); err != nil {
+		return err
+	}
+
+	// Layer the selected profile over the global config
+	profileName, explicitProfile := activeProfile()
+	var profileValues map[string]interface{}
+	if profileName != "" {
+		profile, err := lookupProfile(profileName)
+		if err != nil {
+			// A profile asked for with --profile or MTC_PROFILE must exist,
+			// rather than silently running against the default platform
+			if explicitProfile && !lenient {
+				return exitcode.WithCode(exitcode.Config, err)
+			}
+			slog.Warn("profile is not used", "profile", profileName, "error", err)
+		} else {
+			profileValues = profile.Values()
+			if err := viper.MergeConfigMap(profileValues); err != nil {
+				return err
 			}
 		}
 	}
 
-	// Now, look for a project-specific config in the current directory and merge it.
+	// Now, look for the nearest project-specific config and merge it.
 	wd, err := os.Getwd()
-	cobra.CheckErr(err)
-	viper.AddConfigPath(wd)
-	viper.SetConfigName(".mtc")
-	viper.SetConfigType("json")
+	if err != nil {
+		return err
+	}
+	workspace, err := workspaceRoot()
+	if err != nil {
+		return err
+	}
+	root, found, err := lab.FindRoot(wd, workspace)
+	if err != nil {
+		return err
+	}
+	if found {
+		labRoot = root
+		viper.SetConfigFile(filepath.Join(labRoot, lab.ConfigFile))
+		if err := check(viper.MergeInConfig()); err != nil {
+			return err
+		}
+	}
 
-	// MergeInConfig will not error if the file doesn't exist, which is perfect for us.
-	viper.MergeInConfig()
+	// A profile picked with --profile or MTC_PROFILE wins over the project config
+	if explicitProfile && profileValues != nil {
+		return viper.MergeConfigMap(profileValues)
+	}
+	return nil
+}
+
+// repairsConfig reports whether cmd is used to inspect or fix the config,
+// or is shell completion
+func repairsConfig(cmd *cobra.Command) bool {
+	for ; cmd.HasParent(); cmd = cmd.Parent() {
+		if cmd.Parent().HasParent() {
+			continue
+		}
+		switch cmd.Name() {
+		case configCmd.Name(), profileCmd.Name(), "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+			return true
+		}
+	}
+	return false
+}
+
+// projectDir returns the directory project settings belong in: the
+// discovered lab root, or the working directory outside of a lab
+func projectDir() (string, error) {
+	if labRoot != "" {
+		return labRoot, nil
+	}
+	return os.Getwd()
+}
+
+// activeProfile returns the name of the selected profile and whether it was
+// chosen for this run with --profile or MTC_PROFILE
+func activeProfile() (string, bool) {
+	explicit := rootCmd.PersistentFlags().Changed("profile")
+	if _, ok := os.LookupEnv(config.EnvPrefix + "_PROFILE"); ok {
+		explicit = true
+	}
+	return viper.GetString("profile"), explicit
+}
+
+// lookupProfile reads a named profile from the global config file
+func lookupProfile(name string) (config.Profile, error) {
+	globalPath, err := config.GlobalPath(cfgFile)
+	if err != nil {
+		return config.Profile{}, err
+	}
+
+	values, err := config.ReadFile(globalPath)
+	if err != nil {
+		return config.Profile{}, err
+	}
+
+	profiles, err := config.Profiles(values)
+	if err != nil {
+		return config.Profile{}, err
+	}
+
+	profile, ok := profiles[name]
+	if !ok {
+		return config.Profile{}, fmt.Errorf("profile %q does not exist", name)
+	}
+
+	return profile, nil
+}
+
+// newAPIClient returns an API client for the effective settings
+func newAPIClient() *mtcapi.MtcApiClient {
+	apiClient := mtcapi.New(viper.GetString("api_base_url"))
+	if apiKey := viper.GetString("api_key"); apiKey != "" {
+		apiClient.SetAPIKey(apiKey)
+	}
+	return apiClient
 }
 
 func Execute() {
+	// Cobra has already printed the error. Errors without a code come from
+	// cobra itself and are usage errors, commands report their own failures
+	// through output.Result.
 	err := rootCmd.Execute()
-	cobra.CheckErr(err)
+	if logCloser != nil {
+		logCloser.Close()
+	}
+	if err != nil {
+		code, ok := exitcode.Attached(err)
+		if !ok {
+			code = exitcode.Usage
+		}
+		os.Exit(code)
+	}
+	os.Exit(output.ExitCode())
 }
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/morethancertified/mtc-cli/internal/exitcode"
+	"github.com/morethancertified/mtc-cli/internal/history"
+	"github.com/morethancertified/mtc-cli/internal/output"
+	"github.com/morethancertified/mtc-cli/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// statusSummary counts a lesson's tasks by status
+type statusSummary struct {
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+	Pending   int `json:"pending"`
+	Total     int `json:"total"`
+}
+
+func (s statusSummary) String() string {
+	return fmt.Sprintf("%d/%d tasks complete", s.Completed, s.Total)
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status [lesson-token]",
+	Short: "Show the task status of a lesson",
+	Long: `Show the status of a lesson's tasks without running or submitting anything.
+With --short only a one-line summary such as "3/5 tasks complete" is printed,
+which suits shell prompts and scripts.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeLessonToken,
+	Example:           "mtc status cm4ppz694200blze51ts1234\nmtc status --short",
+	Run: func(cmd *cobra.Command, args []string) {
+		result := output.Result{Command: "status"}
+		defer result.Emit()
+
+		lessonToken, err := lessonTokenArg(args)
+		if err != nil {
+			result.Fail("Error: %s", err)
+			return
+		}
+		result.LessonToken = lessonToken
+
+		reports, err := reportSpecs(cmd)
+		if err != nil {
+			result.Fail("Error: %s", err)
+			return
+		}
+
+		lesson, err := newAPIClient().GetLesson(lessonToken)
+		if err != nil {
+			result.Fail("Error getting lesson: %s", err)
+			return
+		}
+		result.Lesson = output.NewLesson(lesson)
+		result.Tasks = lesson.Tasks
+
+		summary := summarizeTasks(lesson.Tasks)
+		result.Data = summary
+		if len(reports) > 0 {
+			// Attach the output of the last submission made from here, if any
+			var results []types.CLICommandResult
+			if attempts, err := history.List(lessonToken); err == nil && len(attempts) > 0 {
+				results = attempts[len(attempts)-1].Results
+			}
+			writeReports(reports, &result, lesson, results)
+		}
+		if result.ExitCode == exitcode.OK {
+			result.ExitCode = exitcode.ForLesson(lesson.Tasks, nil)
+		}
+
+		if output.Structured() {
+			return
+		}
+		if short, _ := cmd.Flags().GetBool("short"); short {
+			output.Println(summary)
+			return
+		}
+		printTasksTable(lesson.Tasks, nil)
+		output.Println()
+		output.Println(summary)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	addReportFlag(statusCmd)
+	statusCmd.Flags().BoolP("short", "s", false, `Print a one-line summary such as "3/5 tasks complete"`)
+}
+
+func summarizeTasks(tasks []types.Task) statusSummary {
+	summary := statusSummary{Total: len(tasks)}
+	for _, task := range tasks {
+		switch task.Status {
+		case "COMPLETED":
+			summary.Completed++
+		case "FAILED":
+			summary.Failed++
+		default:
+			summary.Pending++
+		}
+	}
+	return summary
+}
@@ -1,169 +1,276 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"strings"
+	"time"
 
-	"github.com/erikgeiser/promptkit/confirmation"
-	"github.com/erikgeiser/promptkit/selection"
+	"github.com/morethancertified/mtc-cli/internal/config"
+	"github.com/morethancertified/mtc-cli/internal/executor"
+	"github.com/morethancertified/mtc-cli/internal/exitcode"
+	"github.com/morethancertified/mtc-cli/internal/lab"
 	"github.com/morethancertified/mtc-cli/internal/mtcapi"
+	"github.com/morethancertified/mtc-cli/internal/output"
 	"github.com/morethancertified/mtc-cli/internal/types"
 	"github.com/morethancertified/mtc-cli/internal/widgets"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"golang.org/x/term"
 )
 
 var submitCmd = &cobra.Command{
-	Use:     "submit <lesson-token>",
-	Short:   "Submit a lesson for grading",
-	Args:    cobra.ExactArgs(1),
-	Example: "mtc submit cm4ppz694200blze51ts1234",
+	Use:               "submit [lesson-token]",
+	Short:             "Submit a lesson for grading",
+	Long:              "Submit a lesson for grading. The lesson token defaults to the one stored by init for the current lab directory.",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeLessonToken,
+	Example:           "mtc submit cm4ppz694200blze51ts1234",
 	Run: func(cmd *cobra.Command, args []string) {
-		// Check for a local project config file and create one if it doesn't exist.
-		wd, err := os.Getwd()
-		cobra.CheckErr(err)
-		localConfigFile := filepath.Join(wd, ".mtc.json")
-
-		if _, err := os.Stat(localConfigFile); os.IsNotExist(err) {
-			fmt.Println("First time submitting for this project.")
-			fmt.Println("Please select the platform this lab is for:")
-
-			// Create a map of display names to API URLs
-			platformMap := map[string]string{
-				"New Learning Platform (https://labs.morethancertified.com/api/v1)": "https://labs.morethancertified.com/api/v1",
-				"Legacy Video Platform (https://app.morethancertified.com/api/v1)":  "https://app.morethancertified.com/api/v1",
-			}
+		result := output.Result{Command: "submit"}
+		defer result.Emit()
 
-			// Create simple string choices for clean display
-			platformOptions := []string{
-				"New Learning Platform (https://labs.morethancertified.com/api/v1)",
-				"Legacy Video Platform (https://app.morethancertified.com/api/v1)",
-			}
+		lessonToken, err := lessonTokenArg(args)
+		if err != nil {
+			result.Fail("Error: %s", err)
+			return
+		}
+		result.LessonToken = lessonToken
 
-			sp := selection.New("Choose the platform:", platformOptions)
-			choice, err := sp.RunPrompt()
-			cobra.CheckErr(err)
+		reports, err := reportSpecs(cmd)
+		if err != nil {
+			result.Fail("Error: %s", err)
+			return
+		}
 
-			// Look up the URL for the selected platform
-			selectedURL := platformMap[choice]
+		// Work out which platform the lab is on the first time we see the project
+		wd, err := projectDir()
+		if err != nil {
+			result.Fail("Error: %s", err)
+			return
+		}
+		localConfig, err := lab.ReadConfig(wd)
+		if err != nil {
+			result.Fail("Error reading %s: %s", lab.ConfigFile, err)
+			return
+		}
 
-			// Create the config map and save it to .mtc.json
-			config := map[string]interface{}{"api_base_url": selectedURL}
-			file, err := json.MarshalIndent(config, "", "  ")
-			cobra.CheckErr(err)
+		if _, ok := localConfig["api_base_url"]; !ok && !platformChosen() {
+			output.Println("First time submitting for this project.")
 
-			err = os.WriteFile(localConfigFile, file, 0644)
-			cobra.CheckErr(err)
+			selectedURL, err := detectPlatform(lessonToken)
+			if err != nil {
+				result.Fail("Error selecting platform: %s", err)
+				return
+			}
 
-			// Set the value for the current run and merge in the new config
+			// Cache the platform in .mtc.json and use it for the current run
+			err = lab.UpdateConfig(wd, map[string]interface{}{"api_base_url": selectedURL})
+			if err != nil {
+				result.Fail("Error saving %s: %s", lab.ConfigFile, err)
+				return
+			}
 			viper.Set("api_base_url", selectedURL)
-			viper.MergeInConfig() // Re-read to ensure it's loaded for this session
-			fmt.Println("Configuration saved to", localConfigFile)
-			fmt.Println("------------------------------------------------------------------")
+			output.Println("Configuration saved to", filepath.Join(wd, lab.ConfigFile))
+			output.Println("------------------------------------------------------------------")
 		}
 
-		lessonToken := args[0]
 		reset, _ := cmd.Flags().GetBool("reset")
-		apiClient := mtcapi.New(viper.GetString("api_base_url"))
+		apiClient := newAPIClient()
 		lesson, err := apiClient.GetLesson(lessonToken)
 		if err != nil {
-			fmt.Println("Error getting lesson:", err)
+			result.Fail("Error getting lesson: %s", err)
+			return
+		}
+		result.Lesson = output.NewLesson(lesson)
+		result.Tasks = lesson.Tasks
+		previous := lesson.Tasks
+
+		if watch, _ := cmd.Flags().GetBool("watch"); watch {
+			if output.Structured() {
+				result.FailWith(exitcode.Usage, "Error: --watch is interactive and does not support --output %s", output.Format())
+				return
+			}
+			autoSubmit, _ := cmd.Flags().GetBool("auto-submit")
+			var warnings []error
+			submit := historySubmitter(apiClient, lessonToken, "watch", &warnings)
+			lesson, err = widgets.RunWatch(submit, lessonToken, wd, lesson, autoSubmit)
+			for _, warning := range warnings {
+				output.Println("Warning:", warning)
+			}
+			if err != nil {
+				result.Fail("Error watching lab: %s", err)
+				return
+			}
+			result.Tasks = lesson.Tasks
+			result.ExitCode = exitcode.ForLesson(lesson.Tasks, nil)
+			printTasksTable(lesson.Tasks, previous)
 			return
 		}
 
 		if reset {
+			result.Command = "reset"
 			lesson, err = apiClient.ResetLesson(lessonToken)
 			if err != nil {
-				fmt.Println("Error resetting lesson:", err)
+				result.Fail("Error resetting lesson: %s", err)
 				return
 			}
-			fmt.Println("\nLesson reset!")
-			printTasksTable(lesson.Tasks)
+			result.Tasks = lesson.Tasks
+			output.Println("\nLesson reset!")
+			printTasksTable(lesson.Tasks, previous)
 			return
 		}
 
-		printTasksTable(lesson.Tasks)
-		fmt.Println("\nWe will now run the following command(s) to validate your lesson:")
-		fmt.Println("------------------------------------------------------------------")
+		printTasksTable(lesson.Tasks, nil)
+		output.Println("\nWe will now run the following command(s) to validate your lesson:")
+		output.Println("------------------------------------------------------------------")
 		for _, command := range lesson.CliCommands {
-			fmt.Println(command)
+			output.Println(command)
 		}
-		fmt.Println("------------------------------------------------------------------")
+		output.Println("------------------------------------------------------------------")
 
-		input := confirmation.New("Continue?", confirmation.Yes)
-		ready, err := input.RunPrompt()
-		if err != nil {
-			fmt.Println("Error getting confirmation:", err)
-			return
-		}
-		if !ready {
-			fmt.Println("Aborting...")
-			return
+		if yes, _ := cmd.Flags().GetBool("yes"); !yes {
+			ready, err := widgets.Confirm("Continue?", true)
+			if err != nil {
+				result.Fail("Error getting confirmation: %s", err)
+				return
+			}
+			if !ready {
+				output.Println("Aborting...")
+				result.Errors = append(result.Errors, "submission was not confirmed")
+				return
+			}
 		}
 
-		widgets.RunProgressBar()
+		if !output.Structured() && !output.Plain() {
+			widgets.RunProgressBar()
+		}
 
 		cliCommandResults := []types.CLICommandResult{}
 		for _, command := range lesson.CliCommands {
-			cliCommandResult := types.CLICommandResult{
-				Command: command,
-			}
-
-			cmd := exec.Command("sh", "-c", "LANG=en_US.UTF-8 "+command)
-
-			b, err := cmd.Output()
-			if ee, ok := err.(*exec.ExitError); ok {
-				cliCommandResult.ExitCode = ee.ExitCode()
-				cliCommandResult.Stderr = strings.TrimRight(string(ee.Stderr), "\n\t\r")
-			} else if err != nil {
-				cliCommandResult.ExitCode = -69
-			} else {
-				cliCommandResult.Stdout = strings.TrimRight(string(b), "\n\t\r")
-			}
-
-			cliCommandResults = append(cliCommandResults, cliCommandResult)
+			cliCommandResults = append(cliCommandResults, executor.Run(command))
 		}
+		result.CommandResults = cliCommandResults
 
-		lesson, err = apiClient.SubmitLesson(lessonToken, cliCommandResults)
+		lesson, recordErr, err := submitLesson(apiClient, lessonToken, "submit", cliCommandResults)
 		if err != nil {
-			fmt.Println("Error submitting lesson:", err)
+			result.Fail("Error submitting lesson: %s", err)
 			return
 		}
+		if recordErr != nil {
+			output.Println("Warning:", recordErr)
+		}
+		result.Tasks = lesson.Tasks
 
-		fmt.Println("\nGrading complete!")
+		writeReports(reports, &result, lesson, cliCommandResults)
+		if result.ExitCode == exitcode.OK {
+			result.ExitCode = exitcode.ForLesson(lesson.Tasks, cliCommandResults)
+		}
+
+		output.Println("\nGrading complete!")
 
-		printTasksTable(lesson.Tasks)
-		fmt.Println()
+		printTasksTable(lesson.Tasks, previous)
+		output.Println()
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(submitCmd)
 	submitCmd.Flags().BoolP("reset", "r", false, "Reset the lesson tasks")
+	submitCmd.Flags().BoolP("yes", "y", false, "Run the validation commands without asking for confirmation")
+	submitCmd.Flags().BoolP("watch", "w", false, "Re-run the validation commands whenever files in the lab change")
+	submitCmd.Flags().Bool("auto-submit", false, "With --watch, submit every run's results for grading")
+	submitCmd.MarkFlagsMutuallyExclusive("watch", "reset")
+	addReportFlag(submitCmd)
 }
 
-func printTasksTable(tasks []types.Task) {
-	fmt.Println("\nTASK STATUS:")
-	fmt.Println("------------")
-	for _, task := range tasks {
-		status := "⚪"
-		if task.Status == "COMPLETED" {
-			status = "✅"
-		} else if task.Status == "FAILED" {
-			status = "❌"
+// platformChosen reports whether the platform was picked for this run with
+// a profile, the --api-base-url flag or MTC_API_BASE_URL
+func platformChosen() bool {
+	if _, explicitProfile := activeProfile(); explicitProfile {
+		return true
+	}
+	if rootCmd.PersistentFlags().Changed("api-base-url") {
+		return true
+	}
+	_, ok := os.LookupEnv(config.EnvPrefix + "_API_BASE_URL")
+	return ok
+}
+
+// detectPlatform probes the known platforms with the lesson token and
+// returns the API base URL of the one that recognises it. The user is only
+// asked to choose when no platform or more than one recognises the token.
+func detectPlatform(lessonToken string) (string, error) {
+	platforms := knownPlatforms()
+
+	var baseURLs []string
+	var probes []mtcapi.Probe
+	seen := map[string]bool{}
+	probed := map[mtcapi.Probe]bool{}
+	for _, platform := range platforms {
+		if !seen[platform.APIBaseURL] {
+			seen[platform.APIBaseURL] = true
+			baseURLs = append(baseURLs, platform.APIBaseURL)
 		}
+		probe := mtcapi.Probe{BaseURL: platform.APIBaseURL, APIKey: platform.APIKey}
+		if !probed[probe] {
+			probed[probe] = true
+			probes = append(probes, probe)
+		}
+	}
 
-		fmt.Printf("%s %s\n", status, task.Title)
+	output.Println("Detecting the platform this lab is for...")
+	matches := mtcapi.DetectPlatforms(probes, lessonToken, 5*time.Second)
+
+	labels := map[string]string{}
+	for _, platform := range platforms {
+		if _, ok := labels[platform.APIBaseURL]; !ok {
+			labels[platform.APIBaseURL] = fmt.Sprintf("%s (%s)", platform.Name, platform.APIBaseURL)
+		}
+	}
+
+	if len(matches) == 1 {
+		output.Println("Detected platform:", labels[matches[0]])
+		return matches[0], nil
+	}
+
+	candidates := matches
+	if len(matches) == 0 {
+		output.Println("No platform recognised this token, please select the platform this lab is for:")
+		candidates = baseURLs
+	} else {
+		output.Println("More than one platform recognised this token, please select the platform this lab is for:")
+	}
+
+	var options []string
+	platformMap := map[string]string{}
+	for _, baseURL := range candidates {
+		options = append(options, labels[baseURL])
+		platformMap[labels[baseURL]] = baseURL
+	}
+
+	choice, err := widgets.Select("Choose the platform:", options)
+	if err != nil {
+		return "", err
+	}
+
+	return platformMap[choice], nil
+}
+
+// printTasksTable prints the task table, highlighting the tasks whose status
+// differs from previous
+func printTasksTable(tasks []types.Task, previous []types.Task) {
+	output.Println("\nTASK STATUS:")
+	output.Println(widgets.RenderTaskTable(tasks, previous, terminalWidth()))
+}
+
+// terminalWidth returns the width of the terminal human output goes to, or
+// 0 when it is not a terminal
+func terminalWidth() int {
+	if f, ok := output.Human.(*os.File); ok {
+		if width, _, err := term.GetSize(int(f.Fd())); err == nil {
+			return width
+		}
 	}
-	// t := table.NewWriter()
-	// t.AppendHeader(table.Row{"Title", "Status"})
-	// for _, task := range tasks {
-	// 	t.AppendRow(table.Row{task.Title, task.Status})
-	// }
-	// t.SetStyle(table.StyleLight)
-	// fmt.Println(t.Render())
+	return 0
 }
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+
+	"github.com/morethancertified/mtc-cli/internal/exitcode"
+	"github.com/morethancertified/mtc-cli/internal/lab"
+)
+
+// lessonTokenArg returns the lesson token passed on the command line or,
+// when there is none, the one init stored in the current lab directory
+func lessonTokenArg(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	workspace, err := workspaceRoot()
+	if err != nil {
+		return "", err
+	}
+	token, _, err := lab.FindLessonToken(wd, workspace)
+	if err != nil {
+		return "", err
+	}
+	if token == "" {
+		return "", exitcode.WithCode(exitcode.Usage, errors.New("no lesson token given and none found for this directory, run mtc init first or pass the token"))
+	}
+
+	return token, nil
+}
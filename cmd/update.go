@@ -4,43 +4,65 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"runtime"
 
 	"github.com/creativeprojects/go-selfupdate"
+	"github.com/morethancertified/mtc-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
-func update(version string) error {
+// updateResult is the data update reports in structured output modes
+type updateResult struct {
+	CurrentVersion string `json:"current_version"`
+	LatestVersion  string `json:"latest_version,omitempty"`
+	Updated        bool   `json:"updated"`
+}
+
+func update(version string) (updateResult, error) {
+	res := updateResult{CurrentVersion: version}
+
+	slog.Info("checking for updates", "current_version", version, "os", runtime.GOOS, "arch", runtime.GOARCH)
 	latest, found, err := selfupdate.DetectLatest(context.Background(), selfupdate.ParseSlug("morethancertified/mtc-cli"))
 	if err != nil {
-		return fmt.Errorf("error occurred while detecting version: %w", err)
+		return res, fmt.Errorf("error occurred while detecting version: %w", err)
 	}
 	if !found {
-		return fmt.Errorf("latest version for %s/%s could not be found from github repository", runtime.GOOS, runtime.GOARCH)
+		return res, fmt.Errorf("latest version for %s/%s could not be found from github repository", runtime.GOOS, runtime.GOARCH)
 	}
+	res.LatestVersion = latest.Version()
+	slog.Info("found latest release", "version", latest.Version(), "asset", latest.AssetName)
 
 	if latest.LessOrEqual(version) {
-		log.Printf("Current version (%s) is the latest", version)
-		return nil
+		output.Printf("Current version (%s) is the latest\n", version)
+		return res, nil
 	}
 
 	exe, err := selfupdate.ExecutablePath()
 	if err != nil {
-		return errors.New("could not locate executable path")
+		return res, errors.New("could not locate executable path")
 	}
+	slog.Info("updating binary", "path", exe, "url", latest.AssetURL)
 	if err := selfupdate.UpdateTo(context.Background(), latest.AssetURL, latest.AssetName, exe); err != nil {
-		return fmt.Errorf("error occurred while updating binary: %w", err)
+		return res, fmt.Errorf("error occurred while updating binary: %w", err)
 	}
-	log.Printf("Successfully updated to version %s", latest.Version())
-	return nil
+	res.Updated = true
+	output.Printf("Successfully updated to version %s\n", latest.Version())
+	return res, nil
 }
 
 var updateCmd = &cobra.Command{
 	Use:   "update",
 	Short: "Update the mtc-cli to the latest version",
 	Run: func(cmd *cobra.Command, args []string) {
-		update(Version)
+		result := output.Result{Command: "update"}
+		defer result.Emit()
+
+		res, err := update(Version)
+		result.Data = res
+		if err != nil {
+			result.Fail("Error updating: %s", err)
+		}
 	},
 }
 
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Source describes where the effective value of a setting came from
+type Source string
+
+const (
+	SourceFlag    Source = "flag"
+	SourceEnv     Source = "env"
+	SourceProfile Source = "profile"
+	SourceLocal   Source = "local file"
+	SourceGlobal  Source = "global file"
+	SourceDefault Source = "default"
+)
+
+// EnvPrefix is the prefix for environment variables overriding settings
+const EnvPrefix = "MTC"
+
+// Key describes a known setting
+type Key struct {
+	Name        string
+	Description string
+	Default     string
+	// Flag is the persistent flag bound to the setting, if any
+	Flag string
+	// Local settings only make sense in the project config
+	Local bool
+}
+
+// Keys lists every setting the CLI understands
+var Keys = []Key{
+	{
+		Name:        "api_base_url",
+		Description: "Base URL of the MoreThanCertified API",
+		Default:     "https://app.morethancertified.com/api/v1",
+		Flag:        "api-base-url",
+	},
+	{
+		Name:        "api_key",
+		Description: "API key sent with every request, usually set per profile",
+	},
+	{
+		Name:        "profile",
+		Description: "Named profile to use, see mtc profile",
+		Flag:        "profile",
+	},
+	{
+		Name:        "lesson_token",
+		Description: "Lesson token for the lab, stored by init in the lab directory",
+		Local:       true,
+	},
+	{
+		Name:        "workspace_root",
+		Description: "Directory labs are initialized under (defaults to the current directory)",
+	},
+	{
+		Name:        "lab_dir_template",
+		Description: "Go template for the lab directory path inside the workspace root",
+		Default:     "{{.Title}}",
+	},
+}
+
+// Lookup returns the known setting with the given name
+func Lookup(name string) (Key, bool) {
+	for _, key := range Keys {
+		if key.Name == name {
+			return key, true
+		}
+	}
+	return Key{}, false
+}
+
+// Names returns the names of all known settings
+func Names() []string {
+	names := make([]string, len(Keys))
+	for i, key := range Keys {
+		names[i] = key.Name
+	}
+	return names
+}
+
+// GlobalNames returns the names of the settings allowed in the global config
+func GlobalNames() []string {
+	var names []string
+	for _, key := range Keys {
+		if !key.Local {
+			names = append(names, key.Name)
+		}
+	}
+	return names
+}
+
+// GlobalPath returns the path of the global config file, honouring an
+// explicit --config path. Without one it returns the first config file found
+// in ~/.config/mtc, or config.json there when none exists yet.
+func GlobalPath(cfgFile string) (string, error) {
+	if cfgFile != "" {
+		return cfgFile, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	configDir := filepath.Join(home, ".config", "mtc")
+	for _, ext := range []string{"json", "yaml", "yml", "toml"} {
+		path := filepath.Join(configDir, "config."+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+
+	return filepath.Join(configDir, "config.json"), nil
+}
+
+// ReadFile reads a JSON, YAML or TOML config file, picking the format from
+// the file extension. A missing file yields an empty config.
+func ReadFile(path string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := unmarshal(path, b, &values); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	return values, nil
+}
+
+// FileMode is the mode config files are written with. They can hold API
+// keys, so only the owner may read them.
+const FileMode = 0600
+
+// WriteFile writes values to a config file in the format matching its
+// extension, creating its directory. An existing file is made private too.
+func WriteFile(path string, values map[string]interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	b, err := marshal(path, values)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, b, FileMode); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of existing files
+	return os.Chmod(path, FileMode)
+}
+
+// SortedKeys returns the keys of a config map in alphabetical order
+func SortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
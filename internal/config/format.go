@@ -0,0 +1,65 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported config file formats, keyed by file extension
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+)
+
+// Format returns the config format for a file based on its extension
+func Format(path string) (string, error) {
+	switch ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), ".")); ext {
+	case "json":
+		return FormatJSON, nil
+	case "yaml", "yml":
+		return FormatYAML, nil
+	case "toml":
+		return FormatTOML, nil
+	default:
+		return "", fmt.Errorf("unsupported config file extension %q, use .json, .yaml or .toml", filepath.Ext(path))
+	}
+}
+
+func unmarshal(path string, b []byte, values *map[string]interface{}) error {
+	format, err := Format(path)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case FormatYAML:
+		return yaml.Unmarshal(b, values)
+	case FormatTOML:
+		return toml.Unmarshal(b, values)
+	default:
+		return json.Unmarshal(b, values)
+	}
+}
+
+func marshal(path string, values map[string]interface{}) ([]byte, error) {
+	format, err := Format(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatYAML:
+		return yaml.Marshal(values)
+	case FormatTOML:
+		return toml.Marshal(values)
+	default:
+		b, err := json.MarshalIndent(values, "", "  ")
+		return append(b, '\n'), err
+	}
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// ProfilesKey is the global config key holding the named profiles
+const ProfilesKey = "profiles"
+
+// Profile is a named set of settings for a platform or account
+type Profile struct {
+	APIBaseURL     string `json:"api_base_url"`
+	APIKey         string `json:"api_key,omitempty"`
+	WorkspaceRoot  string `json:"workspace_root,omitempty"`
+	LabDirTemplate string `json:"lab_dir_template,omitempty"`
+}
+
+// Values returns the settings the profile overrides
+func (p Profile) Values() map[string]interface{} {
+	values := map[string]interface{}{}
+	for k, v := range map[string]string{
+		"api_base_url":     p.APIBaseURL,
+		"api_key":          p.APIKey,
+		"workspace_root":   p.WorkspaceRoot,
+		"lab_dir_template": p.LabDirTemplate,
+	} {
+		if v != "" {
+			values[k] = v
+		}
+	}
+	return values
+}
+
+// Platform is a MoreThanCertified platform the CLI knows about out of the box
+type Platform struct {
+	Name       string
+	APIBaseURL string
+	// APIKey is sent when probing the platform, if set
+	APIKey string
+}
+
+// Platforms lists the built-in platforms
+var Platforms = []Platform{
+	{Name: "New Learning Platform", APIBaseURL: "https://labs.morethancertified.com/api/v1"},
+	{Name: "Legacy Video Platform", APIBaseURL: "https://app.morethancertified.com/api/v1"},
+}
+
+var profileNameRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ValidateProfileName checks a profile name can be stored as a config key
+func ValidateProfileName(name string) error {
+	if !profileNameRe.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q, use letters, digits, - and _", name)
+	}
+	return nil
+}
+
+// Profiles decodes the named profiles from a config map
+func Profiles(values map[string]interface{}) (map[string]Profile, error) {
+	profiles := map[string]Profile{}
+
+	raw, ok := values[ProfilesKey]
+	if !ok || raw == nil {
+		return profiles, nil
+	}
+
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &profiles); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ProfilesKey, err)
+	}
+
+	return profiles, nil
+}
+
+// SetProfiles stores the named profiles in a config map
+func SetProfiles(values map[string]interface{}, profiles map[string]Profile) {
+	if len(profiles) == 0 {
+		delete(values, ProfilesKey)
+		return
+	}
+
+	// Store plain maps so every file format uses the same key names
+	raw := map[string]interface{}{}
+	for name, profile := range profiles {
+		raw[name] = profile.Values()
+	}
+	values[ProfilesKey] = raw
+}
+
+// ProfileNames returns the profile names in alphabetical order
+func ProfileNames(profiles map[string]Profile) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
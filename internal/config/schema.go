@@ -0,0 +1,215 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// CurrentVersion is the schema version written to new global config files
+const CurrentVersion = 1
+
+// VersionKey is the config key holding the schema version
+const VersionKey = "version"
+
+// File is the typed schema of the global config file
+type File struct {
+	Version        int                `json:"version"`
+	APIBaseURL     string             `json:"api_base_url,omitempty"`
+	APIKey         string             `json:"api_key,omitempty"`
+	Profile        string             `json:"profile,omitempty"`
+	WorkspaceRoot  string             `json:"workspace_root,omitempty"`
+	LabDirTemplate string             `json:"lab_dir_template,omitempty"`
+	Profiles       map[string]Profile `json:"profiles,omitempty"`
+}
+
+// migrations upgrade a config map by one schema version; migrations[n]
+// turns a version n file into a version n+1 file
+var migrations = []func(values map[string]interface{}) error{
+	// Version 0 files predate the version field and need nothing else
+	func(values map[string]interface{}) error { return nil },
+}
+
+// Migrate upgrades the global config file at path to the current schema
+// version. The migrated config must be valid, then the original file is
+// backed up next to it and the backup path is returned; it is empty when
+// the file was already up to date.
+func Migrate(path string) (string, error) {
+	// A missing file has nothing to migrate
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+
+	values, err := ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	version, err := schemaVersion(values)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	if version > CurrentVersion {
+		return "", fmt.Errorf("%s: schema version %d is newer than this mtc-cli supports (%d), please run mtc update", path, version, CurrentVersion)
+	}
+	if version == CurrentVersion {
+		return "", nil
+	}
+
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	for v := version; v < CurrentVersion; v++ {
+		if err := migrations[v](values); err != nil {
+			return "", fmt.Errorf("%s: migrating from schema version %d: %w", path, v, err)
+		}
+		values[VersionKey] = v + 1
+	}
+
+	// Leave an invalid file alone so it can be fixed by hand
+	if _, err := Decode(path, values); err != nil {
+		return "", err
+	}
+
+	// Keep the original around in case the migration loses something
+	backup := fmt.Sprintf("%s.v%d.bak", path, version)
+	if err := os.WriteFile(backup, original, FileMode); err != nil {
+		return "", fmt.Errorf("backing up %s: %w", path, err)
+	}
+
+	if err := WriteFile(path, values); err != nil {
+		return "", fmt.Errorf("writing migrated %s: %w", path, err)
+	}
+
+	return backup, nil
+}
+
+// Load reads and validates the global config file at path
+func Load(path string) (File, error) {
+	if _, err := os.Stat(path); err != nil {
+		return File{}, err
+	}
+
+	values, err := ReadFile(path)
+	if err != nil {
+		return File{}, err
+	}
+
+	return Decode(path, values)
+}
+
+// Decode converts a config map to the typed schema and validates it. Error
+// messages name the file and the offending key.
+func Decode(path string, values map[string]interface{}) (File, error) {
+	var file File
+
+	b, err := json.Marshal(values)
+	if err != nil {
+		return file, fmt.Errorf("%s: %w", path, err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return file, fmt.Errorf("%s: %s", path, describeDecodeError(err))
+	}
+
+	if err := file.Validate(); err != nil {
+		return file, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return file, nil
+}
+
+// Validate checks the values of a config file
+func (f File) Validate() error {
+	if err := validateURL("api_base_url", f.APIBaseURL); err != nil {
+		return err
+	}
+	if err := validateTemplate("lab_dir_template", f.LabDirTemplate); err != nil {
+		return err
+	}
+
+	for name, profile := range f.Profiles {
+		prefix := ProfilesKey + "." + name + "."
+		if err := ValidateProfileName(name); err != nil {
+			return fmt.Errorf("%s: %w", ProfilesKey, err)
+		}
+		if profile.APIBaseURL == "" {
+			return fmt.Errorf("%sapi_base_url: is required", prefix)
+		}
+		if err := validateURL(prefix+"api_base_url", profile.APIBaseURL); err != nil {
+			return err
+		}
+		if err := validateTemplate(prefix+"lab_dir_template", profile.LabDirTemplate); err != nil {
+			return err
+		}
+	}
+
+	if f.Profile != "" {
+		if _, ok := f.Profiles[f.Profile]; !ok {
+			return fmt.Errorf("profile: %q is not defined in %s", f.Profile, ProfilesKey)
+		}
+	}
+
+	return nil
+}
+
+func validateURL(key, value string) error {
+	if value == "" {
+		return nil
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s: %q must be an http or https URL", key, value)
+	}
+	return nil
+}
+
+func validateTemplate(key, value string) error {
+	if value == "" {
+		return nil
+	}
+	if _, err := template.New(key).Parse(value); err != nil {
+		return fmt.Errorf("%s: invalid template: %w", key, err)
+	}
+	return nil
+}
+
+func schemaVersion(values map[string]interface{}) (int, error) {
+	raw, ok := values[VersionKey]
+	if !ok {
+		return 0, nil
+	}
+
+	switch v := raw.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case float64:
+		if v == float64(int(v)) {
+			return int(v), nil
+		}
+	}
+
+	return 0, fmt.Errorf("%s: %v is not a whole number", VersionKey, raw)
+}
+
+func describeDecodeError(err error) string {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return fmt.Sprintf("%s: expected %s but got %s", typeErr.Field, typeErr.Type, typeErr.Value)
+	}
+
+	// Unknown keys are reported as `json: unknown field "x"`
+	msg := strings.Replace(strings.TrimPrefix(err.Error(), "json: "), "unknown field", "unknown key", 1)
+	return fmt.Sprintf("%s, supported keys are %s, %s and %s", msg, VersionKey, strings.Join(GlobalNames(), ", "), ProfilesKey)
+}
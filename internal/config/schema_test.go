@@ -0,0 +1,200 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		backup  bool
+		wantErr string
+	}{
+		{"version 0 json", "config.json", `{"api_base_url": "https://example.com"}`, true, ""},
+		{"version 0 yaml", "config.yaml", "api_base_url: https://example.com\n", true, ""},
+		{"version 0 toml", "config.toml", "api_base_url = 'https://example.com'\n", true, ""},
+		{"current json", "config.json", `{"version": 1}`, false, ""},
+		{"current yaml", "config.yaml", "version: 1\n", false, ""},
+		{"current toml", "config.toml", "version = 1\n", false, ""},
+		{"missing file", "", "", false, ""},
+		{"newer version", "config.json", `{"version": 2}`, false, "schema version 2 is newer than this mtc-cli supports (1)"},
+		{"fractional version", "config.json", `{"version": 1.5}`, false, "version: 1.5 is not a whole number"},
+		{"invalid version 0", "config.json", `{"api_base_url": "notaurl"}`, false, `api_base_url: "notaurl" must be an http or https URL`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "config.json")
+			if tt.file != "" {
+				path = filepath.Join(dir, tt.file)
+				if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			backup, err := Migrate(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Migrate error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if !tt.backup {
+				if backup != "" {
+					t.Errorf("Migrate backed up to %s, want no backup", backup)
+				}
+				if entries, _ := os.ReadDir(dir); tt.file != "" && len(entries) != 1 {
+					t.Errorf("Migrate left %d files, want the config untouched", len(entries))
+				}
+				if b, _ := os.ReadFile(path); tt.file != "" && string(b) != tt.content {
+					t.Errorf("Migrate rewrote the config to %q", b)
+				}
+				return
+			}
+
+			if backup != path+".v0.bak" {
+				t.Errorf("backup = %s, want %s.v0.bak", backup, path)
+			}
+			b, err := os.ReadFile(backup)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.content {
+				t.Errorf("backup = %q, want the original %q", b, tt.content)
+			}
+
+			file, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if file.Version != CurrentVersion || file.APIBaseURL != "https://example.com" {
+				t.Errorf("migrated config = %+v, want version %d with the API URL kept", file, CurrentVersion)
+			}
+			for _, p := range []string{path, backup} {
+				if info, err := os.Stat(p); err != nil || info.Mode().Perm() != FileMode {
+					t.Errorf("%s mode = %v, want %o", p, info.Mode().Perm(), FileMode)
+				}
+			}
+
+			// A second run has nothing to do
+			if backup, err := Migrate(path); err != nil || backup != "" {
+				t.Errorf("second Migrate = %q, %v, want nothing to do", backup, err)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	profile := func(values map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"version": 1, "profiles": map[string]interface{}{"local": values}}
+	}
+
+	tests := []struct {
+		name    string
+		values  map[string]interface{}
+		wantErr string
+	}{
+		{"empty", map[string]interface{}{}, ""},
+		{"valid", map[string]interface{}{"version": 1, "api_base_url": "http://localhost:3000/api/v1", "profile": "local", "profiles": map[string]interface{}{"local": map[string]interface{}{"api_base_url": "https://example.com"}}}, ""},
+		{"unknown key", map[string]interface{}{"bogus": true}, `unknown key "bogus", supported keys are version, api_base_url`},
+		{"wrong type", map[string]interface{}{"workspace_root": 5}, "workspace_root: expected string but got number"},
+		{"bad url", map[string]interface{}{"api_base_url": "notaurl"}, `api_base_url: "notaurl" must be an http or https URL`},
+		{"url without host", map[string]interface{}{"api_base_url": "https://"}, `api_base_url: "https://" must be an http or https URL`},
+		{"bad template", map[string]interface{}{"lab_dir_template": "{{.Title"}, "lab_dir_template: invalid template"},
+		{"undefined profile", map[string]interface{}{"profile": "missing"}, `profile: "missing" is not defined in profiles`},
+		{"profile without url", profile(map[string]interface{}{"api_key": "k"}), "profiles.local.api_base_url: is required"},
+		{"profile with bad url", profile(map[string]interface{}{"api_base_url": "ftp://example.com"}), `profiles.local.api_base_url: "ftp://example.com" must be an http or https URL`},
+		{"profile unknown key", profile(map[string]interface{}{"api_base_url": "https://example.com", "colour": "red"}), `unknown key "colour"`},
+		{"bad profile name", map[string]interface{}{"profiles": map[string]interface{}{"a b": map[string]interface{}{"api_base_url": "https://example.com"}}}, `invalid profile name "a b"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode("config.json", tt.values)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Decode error = %v, want none", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Decode error = %v, want %q", err, tt.wantErr)
+			}
+			if err != nil && !strings.HasPrefix(err.Error(), "config.json: ") {
+				t.Errorf("Decode error %q does not name the file", err)
+			}
+		})
+	}
+}
+
+func TestSchemaVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    int
+		wantErr bool
+	}{
+		{"missing", nil, 0, false},
+		{"int", 1, 1, false},
+		{"toml int64", int64(1), 1, false},
+		{"json float", float64(1), 1, false},
+		{"fraction", 1.5, 0, true},
+		{"string", "1", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := map[string]interface{}{}
+			if tt.value != nil {
+				values[VersionKey] = tt.value
+			}
+			got, err := schemaVersion(values)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("schemaVersion(%v) = %d, %v, want %d, error %v", tt.value, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	values := map[string]interface{}{
+		VersionKey:     CurrentVersion,
+		"api_base_url": "https://example.com",
+		ProfilesKey: map[string]interface{}{
+			"local": map[string]interface{}{"api_base_url": "http://localhost:3000", "api_key": "secret"},
+		},
+	}
+
+	for _, ext := range []string{"json", "yaml", "yml", "toml"} {
+		t.Run(ext, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config."+ext)
+			if err := WriteFile(path, values); err != nil {
+				t.Fatal(err)
+			}
+
+			read, err := ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if version, err := schemaVersion(read); err != nil || version != CurrentVersion {
+				t.Errorf("read back version %v (%T), want %d", read[VersionKey], read[VersionKey], CurrentVersion)
+			}
+
+			file, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if file.APIBaseURL != "https://example.com" || file.Profiles["local"].APIKey != "secret" {
+				t.Errorf("read back %+v", file)
+			}
+		})
+	}
+}
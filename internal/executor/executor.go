@@ -0,0 +1,108 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/morethancertified/mtc-cli/internal/types"
+)
+
+// ExitCodeNotRun is reported when a command could not be started at all
+const ExitCodeNotRun = -69
+
+// Run runs a validation command through sh and returns its result the way
+// the grader expects it: stdout for commands that succeed, stderr and the
+// exit code for commands that fail.
+func Run(command string) types.CLICommandResult {
+	return RunStreaming(context.Background(), command, nil)
+}
+
+// RunStreaming is like Run but calls onLine for every line the command
+// writes to stdout or stderr while it runs. onLine may be nil.
+func RunStreaming(ctx context.Context, command string, onLine func(line string, stderr bool)) types.CLICommandResult {
+	result := types.CLICommandResult{Command: command}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", "LANG=en_US.UTF-8 "+command)
+
+	var mu sync.Mutex
+	stdout := &lineWriter{mu: &mu, onLine: onLine}
+	stderr := &lineWriter{mu: &mu, onLine: onLine, stderr: true}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	slog.Debug("running command", "command", command)
+	start := time.Now()
+	err := cmd.Run()
+	stdout.flush()
+	stderr.flush()
+
+	var ee *exec.ExitError
+	if errors.As(err, &ee) {
+		result.ExitCode = ee.ExitCode()
+		result.Stderr = strings.TrimRight(stderr.buf.String(), "\n\t\r")
+	} else if err != nil {
+		result.ExitCode = ExitCodeNotRun
+	} else {
+		result.Stdout = strings.TrimRight(stdout.buf.String(), "\n\t\r")
+	}
+
+	if result.ExitCode == ExitCodeNotRun {
+		slog.Warn("command could not be run", "command", command, "error", err)
+	} else {
+		slog.Info("command finished", "command", command, "exit_code", result.ExitCode, "duration", time.Since(start))
+	}
+	if result.Stderr != "" {
+		slog.Debug("command stderr", "command", command, "stderr", result.Stderr)
+	}
+
+	return result
+}
+
+// lineWriter captures output and, when onLine is set, also hands every
+// complete line to it
+type lineWriter struct {
+	buf     bytes.Buffer
+	partial []byte
+	mu      *sync.Mutex
+	onLine  func(line string, stderr bool)
+	stderr  bool
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	if w.onLine == nil {
+		return len(p), nil
+	}
+
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.emit(strings.TrimRight(string(w.partial[:i]), "\r"))
+		w.partial = w.partial[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// flush hands a trailing line without a newline to onLine
+func (w *lineWriter) flush() {
+	if w.onLine != nil && len(w.partial) > 0 {
+		w.emit(string(w.partial))
+		w.partial = nil
+	}
+}
+
+func (w *lineWriter) emit(line string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onLine(line, w.stderr)
+}
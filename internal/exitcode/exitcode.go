@@ -0,0 +1,107 @@
+package exitcode
+
+import (
+	"errors"
+	"net"
+	"net/url"
+
+	"github.com/morethancertified/mtc-cli/internal/mtcapi"
+	"github.com/morethancertified/mtc-cli/internal/types"
+)
+
+// Process exit codes. Scripts can rely on these, so existing values must
+// never change meaning.
+const (
+	// OK means the command succeeded. For submit and status it also means
+	// every task of the lesson is complete.
+	OK = 0
+	// Error is any failure not covered by a more specific code
+	Error = 1
+	// Usage means the command line was invalid, e.g. an unknown flag or a
+	// missing lesson token
+	Usage = 2
+	// Network means the API could not be reached
+	Network = 3
+	// Auth means the API rejected the API key or the lesson token
+	Auth = 4
+	// ValidationFailed means at least one validation command exited with a
+	// non-zero status and not every task is complete
+	ValidationFailed = 5
+	// Incomplete means the validation commands passed but not every task
+	// is complete
+	Incomplete = 6
+	// Config means a config file is invalid or could not be loaded
+	Config = 7
+)
+
+// codedError carries the exit code for an error
+type codedError struct {
+	code int
+	err  error
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+
+func (e *codedError) Unwrap() error { return e.err }
+
+// WithCode attaches an exit code to err
+func WithCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &codedError{code: code, err: err}
+}
+
+// Attached returns the exit code attached to err with WithCode and whether
+// there is one
+func Attached(err error) (int, bool) {
+	var coded *codedError
+	if errors.As(err, &coded) {
+		return coded.code, true
+	}
+	return 0, false
+}
+
+// ForError returns the exit code for err: the code attached with WithCode,
+// Auth for requests the API rejected or lesson tokens it does not know,
+// Network for requests that did not
+// reach it and Error for anything else
+func ForError(err error) int {
+	var coded *codedError
+	var urlErr *url.Error
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return OK
+	case errors.As(err, &coded):
+		return coded.code
+	case errors.Is(err, mtcapi.ErrUnauthorized):
+		return Auth
+	case errors.As(err, &urlErr), errors.As(err, &netErr):
+		return Network
+	default:
+		return Error
+	}
+}
+
+// ForLesson returns the exit code for a graded lesson: OK when every task
+// is complete, otherwise ValidationFailed when one of the results failed
+// and Incomplete when they all passed
+func ForLesson(tasks []types.Task, results []types.CLICommandResult) int {
+	complete := true
+	for _, task := range tasks {
+		if task.Status != "COMPLETED" {
+			complete = false
+		}
+	}
+	if complete {
+		return OK
+	}
+
+	for _, result := range results {
+		if result.ExitCode != 0 {
+			return ValidationFailed
+		}
+	}
+	return Incomplete
+}
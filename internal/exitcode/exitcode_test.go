@@ -0,0 +1,81 @@
+package exitcode
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/morethancertified/mtc-cli/internal/mtcapi"
+	"github.com/morethancertified/mtc-cli/internal/types"
+)
+
+func TestForError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, OK},
+		{"plain", errors.New("boom"), Error},
+		{"attached", WithCode(Usage, errors.New("no lesson token")), Usage},
+		{"attached and wrapped", fmt.Errorf("loading: %w", WithCode(Config, errors.New("bad key"))), Config},
+		{"unauthorized", &mtcapi.StatusError{StatusCode: http.StatusUnauthorized}, Auth},
+		{"forbidden", &mtcapi.StatusError{StatusCode: http.StatusForbidden}, Auth},
+		{"server error", &mtcapi.StatusError{StatusCode: http.StatusInternalServerError}, Error},
+		{"invalid token", mtcapi.ErrInvalidToken, Auth},
+		{"wrapped invalid token", fmt.Errorf("getting lesson: %w", mtcapi.ErrInvalidToken), Auth},
+		{"url error", &url.Error{Op: "Get", URL: "https://example.com", Err: errors.New("refused")}, Network},
+		{"dns error", &net.DNSError{Err: "no such host", Name: "example.com"}, Network},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ForError(tt.err); got != tt.want {
+				t.Errorf("ForError(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttached(t *testing.T) {
+	if _, ok := Attached(errors.New("boom")); ok {
+		t.Error("Attached found a code on a plain error")
+	}
+	if code, ok := Attached(fmt.Errorf("x: %w", WithCode(Usage, errors.New("boom")))); !ok || code != Usage {
+		t.Errorf("Attached = %d, %v, want %d, true", code, ok, Usage)
+	}
+	if WithCode(Usage, nil) != nil {
+		t.Error("WithCode(Usage, nil) is not nil")
+	}
+}
+
+func TestForLesson(t *testing.T) {
+	task := func(status string) types.Task { return types.Task{Status: status} }
+	result := func(code int) types.CLICommandResult { return types.CLICommandResult{ExitCode: code} }
+
+	tests := []struct {
+		name    string
+		tasks   []types.Task
+		results []types.CLICommandResult
+		want    int
+	}{
+		{"no tasks", nil, nil, OK},
+		{"all complete", []types.Task{task("COMPLETED"), task("COMPLETED")}, []types.CLICommandResult{result(0), result(0)}, OK},
+		{"complete despite failed command", []types.Task{task("COMPLETED")}, []types.CLICommandResult{result(1)}, OK},
+		{"failed command", []types.Task{task("COMPLETED"), task("FAILED")}, []types.CLICommandResult{result(0), result(1)}, ValidationFailed},
+		{"command not run", []types.Task{task("PENDING")}, []types.CLICommandResult{result(-69)}, ValidationFailed},
+		{"passing but incomplete", []types.Task{task("COMPLETED"), task("PENDING")}, []types.CLICommandResult{result(0), result(0)}, Incomplete},
+		{"status only", []types.Task{task("PENDING")}, nil, Incomplete},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ForLesson(tt.tasks, tt.results); got != tt.want {
+				t.Errorf("ForLesson = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
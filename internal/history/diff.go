@@ -0,0 +1,180 @@
+package history
+
+import (
+	"strings"
+
+	"github.com/morethancertified/mtc-cli/internal/types"
+)
+
+// Diff is what changed between two attempts
+type Diff struct {
+	From     int             `json:"from"`
+	To       int             `json:"to"`
+	Tasks    []TaskChange    `json:"tasks,omitempty"`
+	Commands []CommandChange `json:"commands,omitempty"`
+}
+
+// TaskChange is a task whose status differs between two attempts
+type TaskChange struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// CommandChange is a validation command whose result differs between two
+// attempts. Stdout and Stderr are line diffs prefixed with "-", "+" or " ".
+type CommandChange struct {
+	Command      string   `json:"command"`
+	FromExitCode *int     `json:"from_exit_code"`
+	ToExitCode   *int     `json:"to_exit_code"`
+	Stdout       []string `json:"stdout,omitempty"`
+	Stderr       []string `json:"stderr,omitempty"`
+}
+
+// Compare returns the differences between attempts a and b. Tasks are
+// matched by ID and commands by their text, so commands that only ran in
+// one of the attempts are reported with a nil exit code for the other.
+func Compare(a, b Attempt) Diff {
+	diff := Diff{From: a.Number, To: b.Number}
+
+	fromStatus := map[string]string{}
+	for _, task := range a.Tasks {
+		fromStatus[task.ID] = task.Status
+	}
+	for _, task := range b.Tasks {
+		if from := fromStatus[task.ID]; from != task.Status {
+			diff.Tasks = append(diff.Tasks, TaskChange{ID: task.ID, Title: task.Title, From: from, To: task.Status})
+		}
+	}
+
+	fromResults := map[string]types.CLICommandResult{}
+	for _, result := range a.Results {
+		fromResults[result.Command] = result
+	}
+	seen := map[string]bool{}
+	for _, to := range b.Results {
+		seen[to.Command] = true
+		from, ok := fromResults[to.Command]
+		if ok && from == to {
+			continue
+		}
+
+		change := CommandChange{Command: to.Command, ToExitCode: intPtr(to.ExitCode)}
+		if ok {
+			change.FromExitCode = intPtr(from.ExitCode)
+		}
+		change.Stdout = diffLines(from.Stdout, to.Stdout)
+		change.Stderr = diffLines(from.Stderr, to.Stderr)
+		diff.Commands = append(diff.Commands, change)
+	}
+	for _, from := range a.Results {
+		if !seen[from.Command] {
+			diff.Commands = append(diff.Commands, CommandChange{
+				Command:      from.Command,
+				FromExitCode: intPtr(from.ExitCode),
+				Stdout:       diffLines(from.Stdout, ""),
+				Stderr:       diffLines(from.Stderr, ""),
+			})
+		}
+	}
+
+	return diff
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+// maxDiffCells bounds the size of the table diffLines builds. Longer
+// outputs that differ throughout are shown as removed and added in full.
+const maxDiffCells = 1 << 20
+
+// diffLines returns a line diff of a and b, or nil when they are equal
+func diffLines(a, b string) []string {
+	if a == b {
+		return nil
+	}
+	from, to := splitLines(a), splitLines(b)
+
+	// Lines both sides start or end with need no table
+	prefix := 0
+	for prefix < len(from) && prefix < len(to) && from[prefix] == to[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(from)-prefix && suffix < len(to)-prefix && from[len(from)-1-suffix] == to[len(to)-1-suffix] {
+		suffix++
+	}
+
+	var lines []string
+	for _, line := range from[:prefix] {
+		lines = append(lines, " "+line)
+	}
+	lines = append(lines, diffMiddle(from[prefix:len(from)-suffix], to[prefix:len(to)-suffix])...)
+	for _, line := range from[len(from)-suffix:] {
+		lines = append(lines, " "+line)
+	}
+	return lines
+}
+
+// diffMiddle diffs from and to with a longest common subsequence table, or
+// replaces from with to when the table would be larger than maxDiffCells
+func diffMiddle(from, to []string) []string {
+	var lines []string
+	if (len(from)+1)*(len(to)+1) > maxDiffCells {
+		for _, line := range from {
+			lines = append(lines, "-"+line)
+		}
+		for _, line := range to {
+			lines = append(lines, "+"+line)
+		}
+		return lines
+	}
+
+	// Longest common subsequence table, lcs[i][j] covers from[i:] and to[j:]
+	lcs := make([][]int, len(from)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(from) && j < len(to) {
+		switch {
+		case from[i] == to[j]:
+			lines = append(lines, " "+from[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, "-"+from[i])
+			i++
+		default:
+			lines = append(lines, "+"+to[j])
+			j++
+		}
+	}
+	for ; i < len(from); i++ {
+		lines = append(lines, "-"+from[i])
+	}
+	for ; j < len(to); j++ {
+		lines = append(lines, "+"+to[j])
+	}
+
+	return lines
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
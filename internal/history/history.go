@@ -0,0 +1,193 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/morethancertified/mtc-cli/internal/mtcapi"
+	"github.com/morethancertified/mtc-cli/internal/types"
+)
+
+// Attempt is one submission of a lesson for grading
+type Attempt struct {
+	Number      int                      `json:"number"`
+	SubmittedAt time.Time                `json:"submitted_at"`
+	LessonToken string                   `json:"lesson_token"`
+	CLIVersion  string                   `json:"cli_version"`
+	Source      string                   `json:"source"`
+	Commands    []string                 `json:"commands"`
+	Results     []types.CLICommandResult `json:"results"`
+	Tasks       []types.Task             `json:"tasks"`
+}
+
+// Passing counts the attempt's commands that exited successfully
+func (a Attempt) Passing() int {
+	count := 0
+	for _, result := range a.Results {
+		if result.ExitCode == 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// Completed counts the attempt's tasks that were graded as completed
+func (a Attempt) Completed() int {
+	count := 0
+	for _, task := range a.Tasks {
+		if task.Status == "COMPLETED" {
+			count++
+		}
+	}
+	return count
+}
+
+// Dir returns the directory history is kept in, $XDG_DATA_HOME/mtc/history
+// or ~/.local/share/mtc/history
+func Dir() (string, error) {
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		return filepath.Join(dataHome, "mtc", "history"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "mtc", "history"), nil
+}
+
+// path returns the file the attempts for a lesson token are appended to.
+// Tokens are checked first so they cannot point outside of the history.
+func path(lessonToken string) (string, error) {
+	if !mtcapi.ValidCUID(lessonToken) || strings.ContainsAny(lessonToken, `/\.`) {
+		return "", fmt.Errorf("invalid lesson token %q", lessonToken)
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, lessonToken+".jsonl"), nil
+}
+
+// Record numbers the attempt after the ones already stored for its lesson
+// token and appends it to the history
+func Record(attempt *Attempt) error {
+	attempts, err := List(attempt.LessonToken)
+	if err != nil {
+		return err
+	}
+	attempt.Number = len(attempts) + 1
+
+	p, err := path(attempt.LessonToken)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(attempt)
+	if err != nil {
+		return err
+	}
+
+	// Attempts hold command output, which is for the user's eyes only
+	f, err := os.OpenFile(p, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// List returns every attempt stored for a lesson token, oldest first
+func List(lessonToken string) ([]Attempt, error) {
+	p, err := path(lessonToken)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var attempts []Attempt
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var attempt Attempt
+		if err := json.Unmarshal(scanner.Bytes(), &attempt); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", p, line, err)
+		}
+		attempts = append(attempts, attempt)
+	}
+
+	return attempts, scanner.Err()
+}
+
+// Get returns attempt number n for a lesson token
+func Get(lessonToken string, n int) (Attempt, error) {
+	attempts, err := List(lessonToken)
+	if err != nil {
+		return Attempt{}, err
+	}
+	for _, attempt := range attempts {
+		if attempt.Number == n {
+			return attempt, nil
+		}
+	}
+	return Attempt{}, fmt.Errorf("no attempt %d for lesson %s, see mtc history", n, lessonToken)
+}
+
+// Tokens returns the lesson tokens with recorded attempts and when each was
+// last submitted, most recent first
+func Tokens() ([]string, map[string]time.Time, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tokens []string
+	submitted := map[string]time.Time{}
+	for _, entry := range entries {
+		token, ok := strings.CutSuffix(entry.Name(), ".jsonl")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		tokens = append(tokens, token)
+		submitted[token] = info.ModTime()
+	}
+	sort.SliceStable(tokens, func(i, j int) bool {
+		return submitted[tokens[i]].After(submitted[tokens[j]])
+	})
+
+	return tokens, submitted, nil
+}
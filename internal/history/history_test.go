@@ -0,0 +1,235 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/morethancertified/mtc-cli/internal/types"
+)
+
+const testToken = "cabcdefghijklmnopqrstuvwx"
+
+func TestRecordAndList(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	for i := 0; i < 2; i++ {
+		if err := Record(&Attempt{LessonToken: testToken, Commands: []string{"true"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	attempts, err := List(testToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attempts) != 2 || attempts[0].Number != 1 || attempts[1].Number != 2 {
+		t.Fatalf("got attempts %+v, want numbers 1 and 2", attempts)
+	}
+
+	p, err := path(testToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("history file mode = %o, want 600", mode)
+	}
+}
+
+func TestTokens(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	if tokens, _, err := Tokens(); err != nil || len(tokens) != 0 {
+		t.Fatalf("Tokens without a history = %q, %v, want none", tokens, err)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	older, newer := "colderaaaaaaaaaaaaaaaaaaa", "cnewerbbbbbbbbbbbbbbbbbbb"
+	for token, at := range map[string]time.Time{testToken: now.Add(-time.Hour), older: now.Add(-2 * time.Hour), newer: now} {
+		if err := Record(&Attempt{LessonToken: token}); err != nil {
+			t.Fatal(err)
+		}
+		p, err := path(token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(p, at, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dir, err := Dir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tokens, submitted, err := Tokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{newer, testToken, older}; !equal(tokens, want) {
+		t.Errorf("Tokens = %q, want %q", tokens, want)
+	}
+	if !submitted[newer].Equal(now) {
+		t.Errorf("%s last submitted %s, want %s", newer, submitted[newer], now)
+	}
+}
+
+func TestInvalidTokens(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dataHome, "data"))
+
+	for _, token := range []string{"", "../../x", "c../../../escape", `cabcdef\x`, "cabc/def", "xabcdefghij"} {
+		if err := Record(&Attempt{LessonToken: token}); err == nil {
+			t.Errorf("Record accepted lesson token %q", token)
+		}
+		if _, err := List(token); err == nil {
+			t.Errorf("List accepted lesson token %q", token)
+		}
+	}
+
+	entries, _ := os.ReadDir(dataHome)
+	if len(entries) != 0 {
+		t.Errorf("invalid tokens created files in %s", dataHome)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	a := Attempt{
+		Number: 1,
+		Tasks: []types.Task{
+			{ID: "t1", Title: "Create", Status: "FAILED"},
+			{ID: "t2", Title: "Tag", Status: "PENDING"},
+		},
+		Results: []types.CLICommandResult{
+			{Command: "check", ExitCode: 1, Stderr: "missing"},
+			{Command: "same", Stdout: "ok"},
+			{Command: "removed", Stdout: "gone"},
+		},
+	}
+	b := Attempt{
+		Number: 2,
+		Tasks: []types.Task{
+			{ID: "t1", Title: "Create", Status: "COMPLETED"},
+			{ID: "t2", Title: "Tag", Status: "PENDING"},
+			{ID: "t3", Title: "New", Status: "PENDING"},
+		},
+		Results: []types.CLICommandResult{
+			{Command: "check", Stdout: "found"},
+			{Command: "same", Stdout: "ok"},
+			{Command: "added", Stdout: "new"},
+		},
+	}
+
+	diff := Compare(a, b)
+	if diff.From != 1 || diff.To != 2 {
+		t.Errorf("diff is from %d to %d, want 1 to 2", diff.From, diff.To)
+	}
+
+	wantTasks := []TaskChange{
+		{ID: "t1", Title: "Create", From: "FAILED", To: "COMPLETED"},
+		{ID: "t3", Title: "New", From: "", To: "PENDING"},
+	}
+	if len(diff.Tasks) != len(wantTasks) {
+		t.Fatalf("task changes = %+v, want %+v", diff.Tasks, wantTasks)
+	}
+	for i, want := range wantTasks {
+		if diff.Tasks[i] != want {
+			t.Errorf("task change %d = %+v, want %+v", i, diff.Tasks[i], want)
+		}
+	}
+
+	commands := map[string]CommandChange{}
+	for _, change := range diff.Commands {
+		commands[change.Command] = change
+	}
+	if len(commands) != 3 {
+		t.Fatalf("command changes = %+v, want check, added and removed", diff.Commands)
+	}
+	if _, ok := commands["same"]; ok {
+		t.Error("unchanged command reported as changed")
+	}
+	check := commands["check"]
+	if *check.FromExitCode != 1 || *check.ToExitCode != 0 {
+		t.Errorf("check exit codes = %d -> %d, want 1 -> 0", *check.FromExitCode, *check.ToExitCode)
+	}
+	if !equal(check.Stdout, []string{"+found"}) || !equal(check.Stderr, []string{"-missing"}) {
+		t.Errorf("check output diff = %q %q", check.Stdout, check.Stderr)
+	}
+	if added := commands["added"]; added.FromExitCode != nil || added.ToExitCode == nil {
+		t.Errorf("added command exit codes = %v -> %v, want nil -> 0", added.FromExitCode, added.ToExitCode)
+	}
+	if removed := commands["removed"]; removed.FromExitCode == nil || removed.ToExitCode != nil {
+		t.Errorf("removed command exit codes = %v -> %v, want 0 -> nil", removed.FromExitCode, removed.ToExitCode)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []string
+	}{
+		{"equal", "a\nb", "a\nb", nil},
+		{"added", "", "a", []string{"+a"}},
+		{"removed", "a", "", []string{"-a"}},
+		{"changed middle", "a\nb\nc", "a\nx\nc", []string{" a", "-b", "+x", " c"}},
+		{"inserted", "a\nc", "a\nb\nc", []string{" a", "+b", " c"}},
+		{"interleaved", "a\nb\nc\nd", "b\nx\nd\ny", []string{"-a", " b", "-c", "+x", " d", "+y"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffLines(tt.a, tt.b); !equal(got, tt.want) {
+				t.Errorf("diffLines(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLinesLargeOutput(t *testing.T) {
+	var a, b []byte
+	for i := 0; i < 5000; i++ {
+		a = append(a, "same\nold\n"...)
+		b = append(b, "same\nnew\n"...)
+	}
+	a = append(a, "end"...)
+	b = append(b, "end"...)
+
+	lines := diffLines("start\n"+string(a), "start\n"+string(b))
+	if lines[0] != " start" || lines[len(lines)-1] != " end" {
+		t.Errorf("common first and last lines not kept: %q ... %q", lines[0], lines[len(lines)-1])
+	}
+	removed, added := 0, 0
+	for _, line := range lines {
+		switch line[0] {
+		case '-':
+			removed++
+		case '+':
+			added++
+		}
+	}
+	// Everything between the common first two and last lines is replaced
+	if removed != 9999 || added != 9999 {
+		t.Errorf("got %d removed and %d added lines, want 9999 of each", removed, added)
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package lab
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/morethancertified/mtc-cli/internal/config"
+)
+
+// ConfigFile is the name of the per-project config file
+const ConfigFile = ".mtc.json"
+
+// LessonTokenKey is the config key init stores the lesson token under
+const LessonTokenKey = "lesson_token"
+
+// ReadConfig reads the project config in dir. A missing file yields an
+// empty config.
+func ReadConfig(dir string) (map[string]interface{}, error) {
+	return config.ReadFile(filepath.Join(dir, ConfigFile))
+}
+
+// UpdateConfig merges values into the project config in dir, keeping any
+// keys that are already set
+func UpdateConfig(dir string, values map[string]interface{}) error {
+	current, err := ReadConfig(dir)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range values {
+		current[k] = v
+	}
+
+	return config.WriteFile(filepath.Join(dir, ConfigFile), current)
+}
+
+// FindRoot walks up from dir looking for the nearest project config and
+// returns the directory holding it. The search stops below the home
+// directory, the workspace root or the filesystem root, whichever comes
+// first, and workspace may be empty. The returned bool is false when no
+// project config was found.
+func FindRoot(dir, workspace string) (string, bool, error) {
+	dirs, err := searchDirs(dir, workspace)
+	if err != nil {
+		return "", false, err
+	}
+
+	for _, d := range dirs {
+		if _, err := os.Stat(filepath.Join(d, ConfigFile)); err == nil {
+			return d, true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
+// FindLessonToken walks up from dir like FindRoot looking for a project
+// config with a lesson token. It returns the token and the directory it was
+// found in, or an empty token when there is none.
+func FindLessonToken(dir, workspace string) (string, string, error) {
+	dirs, err := searchDirs(dir, workspace)
+	if err != nil {
+		return "", "", err
+	}
+
+	for _, d := range dirs {
+		values, err := ReadConfig(d)
+		if err != nil {
+			return "", "", err
+		}
+		if token, ok := values[LessonTokenKey].(string); ok && token != "" {
+			return token, d, nil
+		}
+	}
+
+	return "", "", nil
+}
+
+// searchDirs returns dir followed by its parents, stopping before the home
+// directory, the workspace root or the filesystem root
+func searchDirs(dir, workspace string) ([]string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	stops := map[string]bool{}
+	if home, err := os.UserHomeDir(); err == nil && home != "" {
+		stops[filepath.Clean(home)] = true
+	}
+	if workspace != "" {
+		if workspace, err := filepath.Abs(workspace); err == nil {
+			stops[workspace] = true
+		}
+	}
+
+	dirs := []string{dir}
+	for {
+		parent := filepath.Dir(dir)
+		// Stop before the filesystem root, which is its own parent
+		if parent == dir || filepath.Dir(parent) == parent || stops[parent] {
+			return dirs, nil
+		}
+		dirs = append(dirs, parent)
+		dir = parent
+	}
+}
@@ -0,0 +1,86 @@
+package lab
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfig(t *testing.T, dir string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ConfigFile), []byte(`{"lesson_token":"cabcdefghij"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindRoot(t *testing.T) {
+	base := t.TempDir()
+	home := filepath.Join(base, "home")
+	workspace := filepath.Join(base, "workspace")
+	t.Setenv("HOME", home)
+
+	// Stray configs at the stop points are never picked up
+	writeConfig(t, base)
+	writeConfig(t, home)
+	writeConfig(t, workspace)
+
+	labDir := filepath.Join(workspace, "course", "lab")
+	writeConfig(t, labDir)
+	nested := filepath.Join(labDir, "modules", "network")
+	for _, dir := range []string{
+		nested,
+		filepath.Join(home, "notes", "deep"),
+		filepath.Join(workspace, "course", "empty"),
+	} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		dir       string
+		workspace string
+		want      string
+	}{
+		{"lab root", labDir, workspace, labDir},
+		{"inside lab", nested, workspace, labDir},
+		{"below home", filepath.Join(home, "notes", "deep"), "", ""},
+		{"below workspace root", filepath.Join(workspace, "course", "empty"), workspace, ""},
+		{"no workspace root", filepath.Join(workspace, "course", "empty"), "", workspace},
+		{"workspace root itself", workspace, workspace, workspace},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, found, err := FindRoot(tt.dir, tt.workspace)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found != (tt.want != "") || root != tt.want {
+				t.Errorf("FindRoot(%s) = %q, %v, want %q", tt.dir, root, found, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchDirsStopsBeforeFilesystemRoot(t *testing.T) {
+	t.Setenv("HOME", "")
+
+	dir := t.TempDir()
+	dirs, err := searchDirs(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dirs[0] != dir {
+		t.Errorf("search starts at %s, want %s", dirs[0], dir)
+	}
+	for _, d := range dirs {
+		if filepath.Dir(d) == d {
+			t.Errorf("search includes the filesystem root %s", d)
+		}
+	}
+}
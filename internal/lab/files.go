@@ -0,0 +1,124 @@
+package lab
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/morethancertified/mtc-cli/internal/types"
+)
+
+// File categories as served by the lab files API
+const (
+	CategoryPublic    = "public"
+	CategoryBootstrap = "bootstrap"
+	CategoryOther     = "other"
+)
+
+// Categories lists the file categories in display order
+var Categories = []string{CategoryPublic, CategoryBootstrap, CategoryOther}
+
+// Category returns the category a lab file belongs to, based on its path
+func Category(file types.LabFile) string {
+	switch {
+	case strings.HasPrefix(file.Path, "public/"):
+		return CategoryPublic
+	case strings.HasPrefix(file.Path, "bootstrap/"):
+		return CategoryBootstrap
+	default:
+		return CategoryOther
+	}
+}
+
+// CountByCategory returns the number of files in each category
+func CountByCategory(files []types.LabFile) map[string]int {
+	counts := map[string]int{}
+	for _, category := range Categories {
+		counts[category] = 0
+	}
+	for _, file := range files {
+		counts[Category(file)]++
+	}
+	return counts
+}
+
+// TargetPath returns the path of a lab file relative to the lab directory.
+// Public files are extracted to the root of the lab directory, everything
+// else keeps its original path.
+func TargetPath(file types.LabFile) string {
+	return strings.TrimPrefix(file.Path, "public/")
+}
+
+// Filter selects lab files by category and glob patterns. Patterns use
+// doublestar semantics and are matched against both the path in the lab
+// directory and the original path from the API. Empty fields match
+// everything.
+type Filter struct {
+	Include    []string
+	Exclude    []string
+	Categories []string
+}
+
+// Validate checks the filter for unknown categories and malformed patterns
+func (f Filter) Validate() error {
+	for _, category := range f.Categories {
+		known := false
+		for _, c := range Categories {
+			if category == c {
+				known = true
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown category %q, expected one of %s", category, strings.Join(Categories, ", "))
+		}
+	}
+
+	for _, pattern := range append(append([]string{}, f.Include...), f.Exclude...) {
+		if !doublestar.ValidatePattern(pattern) {
+			return fmt.Errorf("invalid pattern %q", pattern)
+		}
+	}
+
+	return nil
+}
+
+// Apply returns the files selected by the filter, keeping their order
+func (f Filter) Apply(files []types.LabFile) []types.LabFile {
+	var selected []types.LabFile
+	for _, file := range files {
+		if f.match(file) {
+			selected = append(selected, file)
+		}
+	}
+	return selected
+}
+
+func (f Filter) match(file types.LabFile) bool {
+	if len(f.Categories) > 0 {
+		category := Category(file)
+		found := false
+		for _, c := range f.Categories {
+			if c == category {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(f.Include) > 0 && !matchAny(f.Include, file) {
+		return false
+	}
+
+	return !matchAny(f.Exclude, file)
+}
+
+func matchAny(patterns []string, file types.LabFile) bool {
+	for _, pattern := range patterns {
+		if doublestar.MatchUnvalidated(pattern, file.Path) || doublestar.MatchUnvalidated(pattern, TargetPath(file)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package lab
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/morethancertified/mtc-cli/internal/types"
+)
+
+func TestFilterApply(t *testing.T) {
+	var files []types.LabFile
+	for _, path := range []string{
+		"public/main.tf",
+		"public/modules/network/main.tf",
+		"public/README.md",
+		"bootstrap/setup.sh",
+		"solutions/main.tf",
+	} {
+		files = append(files, types.LabFile{Path: path})
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"empty filter", Filter{}, []string{"public/main.tf", "public/modules/network/main.tf", "public/README.md", "bootstrap/setup.sh", "solutions/main.tf"}},
+		{"category", Filter{Categories: []string{CategoryBootstrap, CategoryOther}}, []string{"bootstrap/setup.sh", "solutions/main.tf"}},
+		{"include by target path", Filter{Include: []string{"*.tf"}}, []string{"public/main.tf"}},
+		{"include by original path", Filter{Include: []string{"**/*.tf"}}, []string{"public/main.tf", "public/modules/network/main.tf", "solutions/main.tf"}},
+		{"exclude", Filter{Exclude: []string{"modules/**", "*.md"}}, []string{"public/main.tf", "bootstrap/setup.sh", "solutions/main.tf"}},
+		{"exclude wins over include", Filter{Include: []string{"**/*.tf"}, Exclude: []string{"solutions/**"}}, []string{"public/main.tf", "public/modules/network/main.tf"}},
+		{"category and include", Filter{Categories: []string{CategoryPublic}, Include: []string{"**/main.tf"}}, []string{"public/main.tf", "public/modules/network/main.tf"}},
+		{"no match", Filter{Include: []string{"*.py"}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, file := range tt.filter.Apply(files) {
+				got = append(got, file.Path)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Apply = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  Filter
+		wantErr string
+	}{
+		{"valid", Filter{Include: []string{"**/*.tf"}, Exclude: []string{"{a,b}/*"}, Categories: Categories}, ""},
+		{"unknown category", Filter{Categories: []string{"secret"}}, `unknown category "secret"`},
+		{"bad include", Filter{Include: []string{"[a-"}}, `invalid pattern "[a-"`},
+		{"bad exclude", Filter{Exclude: []string{"{a,b"}}, `invalid pattern "{a,b"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Validate = %v, want no error", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Validate = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
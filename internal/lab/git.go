@@ -0,0 +1,163 @@
+package lab
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+var baseGitignore = []string{
+	"# mtc lab files",
+	ConfigFile,
+	MetadataFile,
+	"",
+	"# OS and editor files",
+	".DS_Store",
+	"*.swp",
+	".idea/",
+	".vscode/",
+}
+
+var terraformGitignore = []string{
+	"# Terraform",
+	".terraform/",
+	"*.tfstate",
+	"*.tfstate.*",
+	"*.tfplan",
+	"crash.log",
+	"crash.*.log",
+	"override.tf",
+	"override.tf.json",
+	"*_override.tf",
+	"*_override.tf.json",
+	".terraformrc",
+	"terraform.rc",
+}
+
+// Gitignore returns the .gitignore contents for a lab with the given files,
+// adding rules for the tooling the lab uses
+func Gitignore(paths []string) string {
+	lines := append([]string{}, baseGitignore...)
+
+	for _, path := range paths {
+		if strings.HasSuffix(path, ".tf") || strings.HasSuffix(path, ".tf.json") {
+			lines = append(lines, "")
+			lines = append(lines, terraformGitignore...)
+			break
+		}
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Commit identity used when git has none configured, so the first commit
+// does not fail on fresh machines
+const (
+	fallbackGitName  = "mtc"
+	fallbackGitEmail = "mtc@localhost"
+)
+
+// InitGit initializes a git repository in dir, writes the given .gitignore
+// unless the lab already ships one, and commits the lab files at paths,
+// relative to dir, as the first commit. Other files already in dir are left
+// untracked.
+func InitGit(dir string, paths []string, gitignore string) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return errors.New("git is not installed")
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		return fmt.Errorf("%s is already a git repository", dir)
+	}
+
+	gitignorePath := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(gitignorePath); os.IsNotExist(err) {
+		if err := os.WriteFile(gitignorePath, []byte(gitignore), 0644); err != nil {
+			return err
+		}
+	}
+
+	if _, err := git(dir, nil, "init", "--quiet"); err != nil {
+		return err
+	}
+
+	// Naming an ignored file makes git add fail, so leave those out
+	paths = append([]string{".gitignore"}, paths...)
+	ignored, err := git(dir, paths, "check-ignore", "--stdin", "-z")
+	if err != nil && !isExitCode(err, 1) {
+		return err
+	}
+	skip := map[string]bool{}
+	for _, path := range strings.Split(ignored, "\x00") {
+		skip[path] = true
+	}
+	var add []string
+	for _, path := range paths {
+		if !skip[path] {
+			add = append(add, path)
+		}
+	}
+	if _, err := git(dir, add, "--literal-pathspecs", "add", "--pathspec-from-file=-", "--pathspec-file-nul"); err != nil {
+		return err
+	}
+
+	var identity []string
+	if value, _ := git(dir, nil, "config", "user.name"); value == "" && os.Getenv("GIT_AUTHOR_NAME") == "" {
+		identity = append(identity, "-c", "user.name="+fallbackGitName)
+	}
+	if value, _ := git(dir, nil, "config", "user.email"); value == "" && os.Getenv("GIT_AUTHOR_EMAIL") == "" {
+		identity = append(identity, "-c", "user.email="+fallbackGitEmail)
+	}
+	_, err = git(dir, nil, append(identity, "commit", "--quiet", "--message", "Initial lab files")...)
+	return err
+}
+
+// git runs a git command in dir, passing paths NUL-separated on stdin, and
+// returns its trimmed output
+func git(dir string, paths []string, args ...string) (string, error) {
+	c := exec.Command("git", args...)
+	c.Dir = dir
+	if paths != nil {
+		c.Stdin = strings.NewReader(strings.Join(paths, "\x00") + "\x00")
+	}
+	var stderr strings.Builder
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil {
+		return string(out), &gitError{args: args, stderr: strings.TrimSpace(stderr.String()), err: err}
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+type gitError struct {
+	args   []string
+	stderr string
+	err    error
+}
+
+func (e *gitError) Error() string {
+	// Name the subcommand, not the options before it
+	name := ""
+	for i := 0; i < len(e.args) && name == ""; i++ {
+		switch {
+		case e.args[i] == "-c":
+			i++
+		case !strings.HasPrefix(e.args[i], "-"):
+			name = e.args[i]
+		}
+	}
+	if e.stderr == "" {
+		return fmt.Sprintf("git %s: %s", name, e.err)
+	}
+	return fmt.Sprintf("git %s: %s", name, e.stderr)
+}
+
+func (e *gitError) Unwrap() error { return e.err }
+
+// isExitCode reports whether err is a command exiting with code
+func isExitCode(err error, code int) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == code
+}
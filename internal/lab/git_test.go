@@ -0,0 +1,70 @@
+package lab
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInitGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	// No identity configured anywhere, as on a fresh machine
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	for _, name := range []string{"GIT_AUTHOR_NAME", "GIT_AUTHOR_EMAIL", "GIT_COMMITTER_NAME", "GIT_COMMITTER_EMAIL", "EMAIL"} {
+		// Setenv restores the variable after the test
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.tf":             "resource {}",
+		"modules/net/main.tf": "module {}",
+		"weird [name].txt":    "x",
+		"terraform.tfstate":   "{}",
+		"notes.txt":           "not from the lab",
+		ConfigFile:            "{}",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paths := []string{"main.tf", "modules/net/main.tf", "weird [name].txt", "terraform.tfstate"}
+	if err := InitGit(dir, paths, Gitignore(paths)); err != nil {
+		t.Fatal(err)
+	}
+
+	tracked, err := git(dir, nil, "ls-files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ".gitignore\nmain.tf\nmodules/net/main.tf\n\"weird [name].txt\""
+	if tracked != want && tracked != strings.ReplaceAll(want, `"`, "") {
+		t.Errorf("tracked files:\n%s\nwant:\n%s", tracked, want)
+	}
+
+	untracked, err := git(dir, nil, "status", "--porcelain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(untracked, "?? notes.txt") {
+		t.Errorf("notes.txt is not left untracked:\n%s", untracked)
+	}
+
+	if err := InitGit(dir, paths, ""); err == nil {
+		t.Error("InitGit succeeded in an existing repository")
+	}
+}
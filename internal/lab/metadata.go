@@ -0,0 +1,43 @@
+package lab
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/morethancertified/mtc-cli/internal/types"
+)
+
+// MetadataFile is the name of the marker file init writes to a lab directory
+const MetadataFile = ".mtc-lab.json"
+
+// ReadMetadata reads the lab marker from dir. The returned bool is false
+// when dir has no marker.
+func ReadMetadata(dir string) (types.LabMetadata, bool, error) {
+	var meta types.LabMetadata
+
+	b, err := os.ReadFile(filepath.Join(dir, MetadataFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return meta, false, nil
+	}
+	if err != nil {
+		return meta, false, err
+	}
+
+	if err := json.Unmarshal(b, &meta); err != nil {
+		return meta, false, err
+	}
+
+	return meta, true, nil
+}
+
+// WriteMetadata writes the lab marker to dir
+func WriteMetadata(dir string, meta types.LabMetadata) error {
+	b, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, MetadataFile), append(b, '\n'), 0644)
+}
@@ -0,0 +1,68 @@
+package lab
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// ignoredNames are directories and files whose changes never trigger a
+// re-validation, mostly state written by tools and by the CLI itself
+var ignoredNames = map[string]bool{
+	".git":         true,
+	".terraform":   true,
+	"node_modules": true,
+	MetadataFile:   true,
+	ConfigFile:     true,
+}
+
+// Ignored reports whether changes to path, inside the lab at root, should
+// be ignored when watching the lab
+func Ignored(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	for _, part := range strings.Split(filepath.ToSlash(rel), "/") {
+		if ignoredNames[part] {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch returns a watcher for dir and all of its subdirectories. fsnotify
+// does not watch recursively, so directories created later must be added
+// with AddTree.
+func Watch(dir string) (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := AddTree(watcher, dir, dir); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	return watcher, nil
+}
+
+// AddTree adds dir and its subdirectories to watcher, skipping the ones
+// ignored for the lab at root
+func AddTree(watcher *fsnotify.Watcher, root, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if Ignored(root, path) {
+			return filepath.SkipDir
+		}
+		return watcher.Add(path)
+	})
+}
@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Supported log formats
+const (
+	Text = "text"
+	JSON = "json"
+)
+
+// Level returns the log level for a verbosity: warnings by default, info
+// for -v, debug for -vv and above and only errors when quiet
+func Level(verbosity int, quiet bool) slog.Level {
+	switch {
+	case quiet:
+		return slog.LevelError
+	case verbosity >= 2:
+		return slog.LevelDebug
+	case verbosity == 1:
+		return slog.LevelInfo
+	default:
+		return slog.LevelWarn
+	}
+}
+
+// Setup makes a logger at level the default slog logger. Logs go to
+// stderr, or are appended to file when it is set. The returned closer must
+// be closed before the process exits.
+func Setup(level slog.Level, format, file string) (io.Closer, error) {
+	var w io.Writer = os.Stderr
+	var closer io.Closer = io.NopCloser(nil)
+	if file != "" {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return nil, err
+		}
+		f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, err
+		}
+		w, closer = f, f
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch format {
+	case Text:
+		handler = slog.NewTextHandler(w, opts)
+	case JSON:
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		closer.Close()
+		return nil, fmt.Errorf("unknown log format %q, expected text or json", format)
+	}
+
+	slog.SetDefault(slog.New(handler))
+	return closer, nil
+}
+
+// RedactURL drops the query string from a URL, which for presigned
+// download URLs holds credentials
+func RedactURL(url string) string {
+	if i := strings.IndexByte(url, '?'); i >= 0 {
+		return url[:i] + "?REDACTED"
+	}
+	return url
+}
@@ -0,0 +1,49 @@
+package mtcapi
+
+import (
+	"sync"
+	"time"
+)
+
+// Probe is a platform to look for a lesson token on
+type Probe struct {
+	BaseURL string
+	// APIKey is sent with the probe, if set
+	APIKey string
+}
+
+// DetectPlatforms runs every probe in parallel and returns the base URLs
+// that recognise the lesson token, in the order they were given. A base URL
+// probed with several API keys matches when any of them is accepted. Each
+// probe gives up after timeout so an unreachable platform cannot stall the
+// CLI.
+func DetectPlatforms(probes []Probe, lessonToken string, timeout time.Duration) []string {
+	found := make([]bool, len(probes))
+
+	var wg sync.WaitGroup
+	for i, probe := range probes {
+		wg.Add(1)
+		go func(i int, probe Probe) {
+			defer wg.Done()
+			c := New(probe.BaseURL)
+			c.httpClient.SetTimeout(timeout)
+			if probe.APIKey != "" {
+				c.SetAPIKey(probe.APIKey)
+			}
+			if _, err := c.GetLesson(lessonToken); err == nil {
+				found[i] = true
+			}
+		}(i, probe)
+	}
+	wg.Wait()
+
+	var matches []string
+	seen := map[string]bool{}
+	for i, probe := range probes {
+		if found[i] && !seen[probe.BaseURL] {
+			seen[probe.BaseURL] = true
+			matches = append(matches, probe.BaseURL)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,43 @@
+package mtcapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDetectPlatforms(t *testing.T) {
+	lesson := func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"l1","tasks":[{"id":"t1"}]}`))
+	}
+	open := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lesson(w)
+	}))
+	defer open.Close()
+	authed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		lesson(w)
+	}))
+	defer authed.Close()
+	unknown := httptest.NewServer(http.NotFoundHandler())
+	defer unknown.Close()
+
+	probes := []Probe{
+		{BaseURL: unknown.URL},
+		{BaseURL: authed.URL, APIKey: "wrong"},
+		{BaseURL: authed.URL, APIKey: "secret"},
+		{BaseURL: open.URL},
+		{BaseURL: open.URL, APIKey: "other"},
+	}
+	matches := DetectPlatforms(probes, "cabcdefghij", time.Second)
+
+	want := []string{authed.URL, open.URL}
+	if len(matches) != len(want) || matches[0] != want[0] || matches[1] != want[1] {
+		t.Errorf("DetectPlatforms = %v, want %v", matches, want)
+	}
+}
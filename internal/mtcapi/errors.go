@@ -0,0 +1,62 @@
+package mtcapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// ErrUnauthorized is matched by errors for requests the API rejected
+// because of the API key or lesson token
+var ErrUnauthorized = errors.New("unauthorized")
+
+// ErrInvalidToken is returned when the API does not know a lesson token. It
+// matches ErrUnauthorized.
+var ErrInvalidToken error = invalidTokenError{}
+
+type invalidTokenError struct{}
+
+func (invalidTokenError) Error() string { return "token is invalid" }
+
+func (invalidTokenError) Is(target error) bool { return target == ErrUnauthorized }
+
+// StatusError is an error response from the API
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	return e.Message
+}
+
+// Is makes 401 and 403 responses match ErrUnauthorized
+func (e *StatusError) Is(target error) bool {
+	return target == ErrUnauthorized &&
+		(e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden)
+}
+
+// statusError builds a StatusError for res, formatting its body with format
+func statusError(res *resty.Response, format string) error {
+	return &StatusError{StatusCode: res.StatusCode(), Message: fmt.Sprintf(format, res.String())}
+}
+
+// authError returns an error when the API rejected the request because of
+// the API key or lesson token, and nil otherwise
+func authError(res *resty.Response) error {
+	if res.StatusCode() == http.StatusUnauthorized || res.StatusCode() == http.StatusForbidden {
+		return statusError(res, "not authorized: %s")
+	}
+	return nil
+}
+
+// tokenError is like authError but also returns ErrInvalidToken when the
+// API does not know the lesson token of the request
+func tokenError(res *resty.Response) error {
+	if res.StatusCode() == http.StatusNotFound {
+		return ErrInvalidToken
+	}
+	return authError(res)
+}
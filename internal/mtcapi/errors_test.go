@@ -0,0 +1,60 @@
+package mtcapi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetLessonErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+		auth   bool
+	}{
+		{"unknown token", http.StatusNotFound, `{"message":"not found"}`, ErrInvalidToken, true},
+		{"no tasks", http.StatusOK, `{"tasks":[]}`, ErrInvalidToken, true},
+		{"unauthorized", http.StatusUnauthorized, `{"message":"bad key"}`, ErrUnauthorized, true},
+		{"forbidden", http.StatusForbidden, `{"message":"bad key"}`, ErrUnauthorized, true},
+		{"server error", http.StatusInternalServerError, `{"message":"oops"}`, &StatusError{StatusCode: http.StatusInternalServerError}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := New(server.URL).GetLesson("cabcdefghij")
+			if want, ok := tt.want.(*StatusError); ok {
+				var statusErr *StatusError
+				if !errors.As(err, &statusErr) || statusErr.StatusCode != want.StatusCode {
+					t.Fatalf("GetLesson error = %v, want status %d", err, want.StatusCode)
+				}
+			} else if !errors.Is(err, tt.want) {
+				t.Fatalf("GetLesson error = %v, want %v", err, tt.want)
+			}
+			if errors.Is(err, ErrUnauthorized) != tt.auth {
+				t.Errorf("GetLesson error %v matches ErrUnauthorized: %v, want %v", err, !tt.auth, tt.auth)
+			}
+			if errors.Is(err, ErrInvalidToken) && !errors.Is(tt.want, ErrInvalidToken) {
+				t.Errorf("GetLesson error %v reports an invalid token", err)
+			}
+		})
+	}
+}
+
+func TestStatusErrorIs(t *testing.T) {
+	if errors.Is(&StatusError{StatusCode: http.StatusInternalServerError}, ErrUnauthorized) {
+		t.Error("a 500 response matches ErrUnauthorized")
+	}
+	if errors.Is(ErrUnauthorized, ErrInvalidToken) {
+		t.Error("ErrUnauthorized matches ErrInvalidToken")
+	}
+}
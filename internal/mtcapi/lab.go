@@ -2,7 +2,11 @@ package mtcapi
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"strings"
 
 	"github.com/morethancertified/mtc-cli/internal/types"
 )
@@ -11,13 +15,17 @@ import (
 func (c *MtcApiClient) GetLabInfo(userLessonID string) (types.LabInfo, error) {
 	res, err := c.httpClient.R().
 		SetResult(&types.LabInfo{}).
-		Get("/labs/" + userLessonID)
+		SetPathParam("token", userLessonID).
+		Get("/labs/{token}")
 	if err != nil {
 		return types.LabInfo{}, err
 	}
 
+	if err := tokenError(res); err != nil {
+		return types.LabInfo{}, err
+	}
 	if res.IsError() {
-		return types.LabInfo{}, fmt.Errorf("API error: %s", res.String())
+		return types.LabInfo{}, statusError(res, "API error: %s")
 	}
 
 	return *res.Result().(*types.LabInfo), nil
@@ -27,13 +35,14 @@ func (c *MtcApiClient) GetLabInfo(userLessonID string) (types.LabInfo, error) {
 func (c *MtcApiClient) GetLabFiles(userLessonID string) ([]types.LabFile, error) {
 	// Make a single request and print the raw response for debugging
 	rawRes, err := c.httpClient.R().
-		Get("/labs/" + userLessonID + "/files")
+		SetPathParam("token", userLessonID).
+		Get("/labs/{token}/files")
 	if err != nil {
 		return nil, err
 	}
 
 	if rawRes.IsError() {
-		return nil, fmt.Errorf("API error: %s", rawRes.String())
+		return nil, statusError(rawRes, "API error: %s")
 	}
 
 	// No need for debug printing in production code
@@ -80,13 +89,14 @@ func (c *MtcApiClient) GetLabFiles(userLessonID string) ([]types.LabFile, error)
 func (c *MtcApiClient) GetLabPublicFiles(userLessonID string) ([]types.LabFile, error) {
 	// Make a single request and print the raw response for debugging
 	rawRes, err := c.httpClient.R().
-		Get("/labs/" + userLessonID + "/files/public")
+		SetPathParam("token", userLessonID).
+		Get("/labs/{token}/files/public")
 	if err != nil {
 		return nil, err
 	}
 
 	if rawRes.IsError() {
-		return nil, fmt.Errorf("API error: %s", rawRes.String())
+		return nil, statusError(rawRes, "API error: %s")
 	}
 
 	// No need for debug printing in production code
@@ -127,14 +137,50 @@ func (c *MtcApiClient) GetLabPublicFiles(userLessonID string) ([]types.LabFile,
 func (c *MtcApiClient) GetLabFileURL(userLessonID string, filePath string) (types.LabFileURL, error) {
 	res, err := c.httpClient.R().
 		SetResult(&types.LabFileURL{}).
-		Get("/labs/" + userLessonID + "/files/" + filePath)
+		SetPathParam("token", userLessonID).
+		SetRawPathParam("path", filePath).
+		Get("/labs/{token}/files/{path}")
 	if err != nil {
 		return types.LabFileURL{}, err
 	}
 
 	if res.IsError() {
-		return types.LabFileURL{}, fmt.Errorf("API error: %s", res.String())
+		return types.LabFileURL{}, statusError(res, "API error: %s")
 	}
 
 	return *res.Result().(*types.LabFileURL), nil
 }
+
+// GetLabReadme fetches the markdown contents of the lab's README
+func (c *MtcApiClient) GetLabReadme(labInfo types.LabInfo) (string, error) {
+	if labInfo.S3Paths.Readme == "" {
+		return "", errors.New("lab has no README")
+	}
+
+	// The README path is an S3 key, the file API expects it relative to the lab
+	readmePath := strings.TrimPrefix(labInfo.S3Paths.Readme, labInfo.S3Paths.Base)
+	readmePath = strings.TrimPrefix(readmePath, "/")
+
+	fileURL, err := c.GetLabFileURL(labInfo.UserLessonID, readmePath)
+	if err != nil {
+		return "", err
+	}
+
+	// The URL is presigned for the storage host, which must not see the API
+	// key, so it is fetched without the API client's auth header
+	res, err := http.Get(fileURL.URL)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error downloading README: %s", res.Status)
+	}
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,47 @@
+package mtcapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/morethancertified/mtc-cli/internal/types"
+)
+
+func TestGetLabReadmeWithoutAPIKey(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/labs/cabcdefghij/files/README.md":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"url":%q}`, server.URL+"/dl/README.md?X-Amz-Signature=abc")
+		case "/dl/README.md":
+			if auth := r.Header.Get("Authorization"); auth != "" {
+				t.Errorf("storage host got Authorization %q", auth)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte("# Lab"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := New(server.URL)
+	client.SetAPIKey("secret")
+
+	var info types.LabInfo
+	info.UserLessonID = "cabcdefghij"
+	info.S3Paths.Base = "labs/lab-1"
+	info.S3Paths.Readme = "labs/lab-1/README.md"
+
+	readme, err := client.GetLabReadme(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if readme != "# Lab" {
+		t.Errorf("GetLabReadme = %q, want %q", readme, "# Lab")
+	}
+}
@@ -1,13 +1,27 @@
 package mtcapi
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
 	"strings"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/morethancertified/mtc-cli/internal/logging"
 	"github.com/morethancertified/mtc-cli/internal/types"
 )
 
+// routeKey is the context key of the route a request was made for
+type routeKey struct{}
+
+// requestRoute returns the route of req, which unlike its URL holds no
+// lesson token
+func requestRoute(req *resty.Request) string {
+	route, _ := req.Context().Value(routeKey{}).(string)
+	return route
+}
+
 type MtcApiClient struct {
 	BaseURL    string
 	httpClient *resty.Client
@@ -16,6 +30,35 @@ type MtcApiClient struct {
 func New(baseURL string) *MtcApiClient {
 	httpClient := resty.New()
 	httpClient.SetBaseURL(baseURL)
+	httpClient.OnBeforeRequest(func(_ *resty.Client, req *resty.Request) error {
+		// Lesson tokens are passed as path parameters, so before resty fills
+		// them in the URL is the route they can be logged by
+		route := logging.RedactURL(req.URL)
+		req.SetContext(context.WithValue(req.Context(), routeKey{}, route))
+		slog.Debug("api request", "method", req.Method, "route", route)
+		return nil
+	})
+	httpClient.OnAfterResponse(func(_ *resty.Client, res *resty.Response) error {
+		level := slog.LevelDebug
+		if res.IsError() {
+			level = slog.LevelWarn
+		}
+		slog.Log(context.Background(), level, "api response",
+			"method", res.Request.Method,
+			"route", requestRoute(res.Request),
+			"status", res.StatusCode(),
+			"duration", res.Time(),
+		)
+		return nil
+	})
+	httpClient.OnError(func(req *resty.Request, err error) {
+		// URL errors repeat the URL with the token
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		slog.Warn("api request failed", "method", req.Method, "route", requestRoute(req), "error", err)
+	})
 
 	return &MtcApiClient{
 		BaseURL:    baseURL,
@@ -23,19 +66,31 @@ func New(baseURL string) *MtcApiClient {
 	}
 }
 
+// SetAPIKey sends key as a bearer token with every request
+func (c *MtcApiClient) SetAPIKey(key string) {
+	c.httpClient.SetAuthToken(key)
+}
+
 func (c *MtcApiClient) GetLesson(lessonToken string) (types.Lesson, error) {
 	res, err := c.httpClient.R().
 		// SetDebug(true).
 		SetResult(&types.Lesson{}).
-		Get("/lessons/" + lessonToken)
+		SetPathParam("token", lessonToken).
+		Get("/lessons/{token}")
 	if err != nil {
 		return types.Lesson{}, err
 	}
+	if err := tokenError(res); err != nil {
+		return types.Lesson{}, err
+	}
+	if res.IsError() {
+		return types.Lesson{}, statusError(res, "%s")
+	}
 
 	results := *res.Result().(*types.Lesson)
 
 	if len(results.Tasks) == 0 {
-		return types.Lesson{}, fmt.Errorf("token is invalid")
+		return types.Lesson{}, ErrInvalidToken
 	}
 
 	return results, nil
@@ -49,13 +104,17 @@ func (c *MtcApiClient) SubmitLesson(lessonToken string, cliCommandResults []type
 			CliCommandResults: cliCommandResults,
 		}).
 		SetResult(&types.Lesson{}).
-		Post("/lessons/" + lessonToken + "/submit")
+		SetPathParam("token", lessonToken).
+		Post("/lessons/{token}/submit")
 	if err != nil {
 		return types.Lesson{}, err
 	}
 
+	if err := tokenError(res); err != nil {
+		return types.Lesson{}, err
+	}
 	if res.IsError() {
-		return types.Lesson{}, fmt.Errorf("%s", res.String())
+		return types.Lesson{}, statusError(res, "%s")
 	}
 
 	return *res.Result().(*types.Lesson), nil
@@ -64,10 +123,17 @@ func (c *MtcApiClient) SubmitLesson(lessonToken string, cliCommandResults []type
 func (c *MtcApiClient) ResetLesson(lessonToken string) (types.Lesson, error) {
 	res, err := c.httpClient.R().
 		SetResult(&types.Lesson{}).
-		Post("/lessons/" + lessonToken + "/reset")
+		SetPathParam("token", lessonToken).
+		Post("/lessons/{token}/reset")
 	if err != nil {
 		return types.Lesson{}, err
 	}
+	if err := tokenError(res); err != nil {
+		return types.Lesson{}, err
+	}
+	if res.IsError() {
+		return types.Lesson{}, statusError(res, "%s")
+	}
 	return *res.Result().(*types.Lesson), nil
 }
 
@@ -0,0 +1,90 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Supported output formats
+const (
+	Text = "text"
+	JSON = "json"
+	YAML = "yaml"
+)
+
+// Formats lists the supported output formats
+var Formats = []string{Text, JSON, YAML}
+
+var format = Text
+
+// Human receives messages meant for people. It is stdout in text mode and
+// stderr otherwise, so that structured output on stdout stays parseable.
+var Human io.Writer = os.Stdout
+
+// SetFormat selects the output format for the rest of the run
+func SetFormat(f string) error {
+	switch f {
+	case Text:
+		Human = os.Stdout
+	case JSON, YAML:
+		Human = os.Stderr
+	default:
+		return fmt.Errorf("unknown output format %q, expected text, json or yaml", f)
+	}
+	format = f
+	return nil
+}
+
+// Format returns the selected output format
+func Format() string {
+	return format
+}
+
+// Structured reports whether a machine-readable format was selected
+func Structured() bool {
+	return format != Text
+}
+
+// Println prints a human message
+func Println(a ...interface{}) {
+	fmt.Fprintln(Human, a...)
+}
+
+// Printf prints a formatted human message
+func Printf(f string, a ...interface{}) {
+	fmt.Fprintf(Human, f, a...)
+}
+
+// Emit writes v to stdout in the selected structured format. It does
+// nothing in text mode.
+func Emit(v interface{}) error {
+	switch format {
+	case JSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case YAML:
+		// Go through JSON so both formats share the same field names
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var doc interface{}
+		if err := json.Unmarshal(b, &doc); err != nil {
+			return err
+		}
+		b, err = yaml.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(b)
+		return err
+	default:
+		return nil
+	}
+}
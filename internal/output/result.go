@@ -0,0 +1,123 @@
+package output
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/morethancertified/mtc-cli/internal/exitcode"
+	"github.com/morethancertified/mtc-cli/internal/types"
+)
+
+// Result is the document every command prints on stdout in json and yaml
+// mode. Fields that do not apply to a command are omitted, so consumers
+// should treat every field except command, success and exit_code as
+// optional.
+type Result struct {
+	// Command is the command that ran, e.g. "submit" or "lab info"
+	Command string `json:"command"`
+	// Success is false when the command hit an error
+	Success bool `json:"success"`
+	// ExitCode is the exit status of the process, see package exitcode
+	ExitCode    int    `json:"exit_code"`
+	LessonToken string `json:"lesson_token,omitempty"`
+	// Lesson describes the lesson without its tasks, see Tasks
+	Lesson *Lesson `json:"lesson,omitempty"`
+	// Tasks holds the task statuses after the command ran
+	Tasks []types.Task `json:"tasks,omitempty"`
+	// CommandResults holds the validation commands run by submit
+	CommandResults []types.CLICommandResult `json:"command_results,omitempty"`
+	// Lab and Files describe the lab downloaded by init
+	Lab   *Lab   `json:"lab,omitempty"`
+	Files []File `json:"files,omitempty"`
+	// Data holds the payload of commands that do not fit the fields above
+	Data   interface{} `json:"data,omitempty"`
+	Errors []string    `json:"errors,omitempty"`
+}
+
+// Lesson is the lesson part of a Result
+type Lesson struct {
+	ID          string    `json:"id"`
+	CliCommands []string  `json:"cli_commands"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NewLesson returns the Result representation of a lesson
+func NewLesson(lesson types.Lesson) *Lesson {
+	return &Lesson{
+		ID:          lesson.ID,
+		CliCommands: lesson.CliCommands,
+		CreatedAt:   lesson.CreatedAt,
+		UpdatedAt:   lesson.UpdatedAt,
+	}
+}
+
+// Lab is the lab part of a Result
+type Lab struct {
+	Title       string `json:"title"`
+	CourseTitle string `json:"course_title"`
+	Directory   string `json:"directory"`
+}
+
+// File statuses reported by init
+const (
+	FileDownloaded = "downloaded"
+	FileLinked     = "linked"
+	FileListed     = "listed"
+	FileFailed     = "failed"
+)
+
+// File is a lab file handled by init
+type File struct {
+	Path     string `json:"path"`
+	Target   string `json:"target"`
+	Category string `json:"category"`
+	Size     int64  `json:"size"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Fail prints a human error message and records it in the result. Unless
+// an earlier failure set one, the exit code is derived from the first error
+// among the arguments with exitcode.ForError.
+func (r *Result) Fail(f string, a ...interface{}) {
+	code := exitcode.Error
+	for _, arg := range a {
+		if err, ok := arg.(error); ok {
+			code = exitcode.ForError(err)
+			break
+		}
+	}
+	r.FailWith(code, f, a...)
+}
+
+// FailWith is like Fail with an explicit exit code
+func (r *Result) FailWith(code int, f string, a ...interface{}) {
+	msg := fmt.Sprintf(f, a...)
+	Println(msg)
+	r.Errors = append(r.Errors, msg)
+	if r.ExitCode == exitcode.OK {
+		r.ExitCode = code
+	}
+}
+
+// exitCode is the exit code of the last result emitted
+var exitCode = exitcode.OK
+
+// ExitCode returns the exit code of the last result emitted
+func ExitCode() int {
+	return exitCode
+}
+
+// Emit marks the result successful when no errors were recorded and
+// writes it out. It is meant to be deferred at the start of a command.
+func (r *Result) Emit() {
+	r.Success = len(r.Errors) == 0
+	if !r.Success && r.ExitCode == exitcode.OK {
+		r.ExitCode = exitcode.Error
+	}
+	exitCode = r.ExitCode
+	if err := Emit(r); err != nil {
+		fmt.Fprintln(Human, "Error writing output:", err)
+	}
+}
@@ -0,0 +1,45 @@
+package output
+
+import (
+	"io"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"golang.org/x/term"
+)
+
+var (
+	plain bool
+	color = true
+)
+
+// SetPlain decides how human output looks for the rest of the run and must
+// be called after SetFormat. Plain output uses ASCII markers, no animations
+// and line-based prompts. It is used when forced, with TERM=dumb and when
+// human output does not go to a terminal. Colours are also turned off in
+// plain mode and when NO_COLOR is set.
+func SetPlain(force bool) {
+	plain = force || os.Getenv("TERM") == "dumb" || !IsTerminal(Human)
+	color = !plain && os.Getenv("NO_COLOR") == ""
+
+	if !color {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+}
+
+// Plain reports whether plain output was selected
+func Plain() bool {
+	return plain
+}
+
+// Color reports whether human output may be coloured
+func Color() bool {
+	return color
+}
+
+// IsTerminal reports whether w is a terminal
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
@@ -0,0 +1,146 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut *junitOutput  `xml:"system-out,omitempty"`
+}
+
+type junitOutput struct {
+	Text string `xml:",cdata"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",cdata"`
+}
+
+// writeJUnit writes the report as JUnit XML with one test case per task.
+// Failed tasks are failures and tasks that were not graded yet are skipped.
+// Command output goes with its task when the commands pair up with the
+// tasks, otherwise the commands get a test suite of their own.
+func writeJUnit(w io.Writer, r Report) error {
+	timestamp := r.GeneratedAt.UTC().Format(time.RFC3339)
+	results, paired := r.Lesson.TaskResults(r.Results)
+
+	suite := junitTestSuite{
+		Name:      "mtc lesson " + r.LessonToken,
+		Tests:     len(r.Lesson.Tasks),
+		Timestamp: timestamp,
+	}
+	for i, task := range r.Lesson.Tasks {
+		var output string
+		if paired {
+			output = xmlText(commandOutput(results[i]))
+		}
+
+		testCase := junitTestCase{
+			Name:      xmlText(task.Title),
+			ClassName: "mtc." + r.Lesson.ID,
+		}
+		switch task.Status {
+		case "COMPLETED":
+		case "FAILED":
+			suite.Failures++
+			testCase.Failure = &junitMessage{
+				Message: "Task failed validation",
+				Type:    task.Status,
+				Text:    output,
+			}
+		default:
+			suite.Skipped++
+			testCase.Skipped = &junitMessage{Message: "Task has not been completed yet (" + task.Status + ")"}
+			if output != "" {
+				testCase.SystemOut = &junitOutput{Text: output}
+			}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	suites := junitTestSuites{Name: "mtc", Suites: []junitTestSuite{suite}}
+
+	if !paired && len(r.Results) > 0 {
+		commands := junitTestSuite{
+			Name:      "mtc validation commands " + r.LessonToken,
+			Tests:     len(r.Results),
+			Timestamp: timestamp,
+		}
+		for _, result := range r.Results {
+			testCase := junitTestCase{
+				Name:      xmlText(result.Command),
+				ClassName: "mtc." + r.Lesson.ID + ".commands",
+			}
+			if result.ExitCode != 0 {
+				commands.Failures++
+				testCase.Failure = &junitMessage{
+					Message: fmt.Sprintf("Command exited with code %d", result.ExitCode),
+					Text:    xmlText(commandOutput(result)),
+				}
+			}
+			commands.Cases = append(commands.Cases, testCase)
+		}
+		suites.Suites = append(suites.Suites, commands)
+	}
+
+	for _, s := range suites.Suites {
+		suites.Tests += s.Tests
+		suites.Failures += s.Failures
+		suites.Skipped += s.Skipped
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// xmlText drops the characters XML 1.0 does not allow, even in CDATA
+// sections, such as the control characters commands print. encoding/xml
+// already splits CDATA sections around "]]>".
+func xmlText(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r',
+			r >= 0x20 && r <= 0xD7FF,
+			r >= 0xE000 && r <= 0xFFFD,
+			r >= 0x10000 && r <= 0x10FFFF:
+			return r
+		default:
+			return -1
+		}
+	}, s)
+}
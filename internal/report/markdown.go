@@ -0,0 +1,107 @@
+package report
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"github.com/morethancertified/mtc-cli/internal/types"
+)
+
+// writeMarkdown writes the report as a markdown task table followed by the
+// output of the commands behind every task that is not complete, or of
+// every failed command when the commands do not pair up with the tasks.
+// The GitHub variant folds the output into <details> blocks so step
+// summaries stay short.
+func writeMarkdown(w io.Writer, r Report, github bool) error {
+	var err error
+
+	write(w, &err, "## MTC lesson %s\n\n", r.LessonToken)
+	write(w, &err, "**%d/%d tasks complete**\n\n", r.Completed(), len(r.Lesson.Tasks))
+
+	write(w, &err, "| Status | Task | Last updated |\n")
+	write(w, &err, "| --- | --- | --- |\n")
+	for _, task := range r.Lesson.Tasks {
+		updated := "-"
+		if !task.UpdatedAt.IsZero() {
+			updated = task.UpdatedAt.UTC().Format(time.RFC3339)
+		}
+		write(w, &err, "| %s %s | %s | %s |\n", statusIcon(task.Status), task.Status, escapeCell(task.Title), updated)
+	}
+
+	if results, paired := r.Lesson.TaskResults(r.Results); paired {
+		for i, task := range r.Lesson.Tasks {
+			if task.Status != "COMPLETED" {
+				writeOutput(w, &err, statusIcon(task.Status)+" ", task.Title, results[i], github)
+			}
+		}
+	} else if len(r.Results) > 0 {
+		write(w, &err, "\n### Validation commands\n\n")
+		write(w, &err, "| Status | Command | Exit code |\n")
+		write(w, &err, "| --- | --- | --- |\n")
+		for _, result := range r.Results {
+			icon := statusIcon("COMPLETED")
+			if result.ExitCode != 0 {
+				icon = statusIcon("FAILED")
+			}
+			write(w, &err, "| %s | %s | %d |\n", icon, escapeCell(result.Command), result.ExitCode)
+		}
+		for _, result := range r.failed() {
+			writeOutput(w, &err, statusIcon("FAILED")+" ", result.Command, result, github)
+		}
+	}
+
+	write(w, &err, "\n_Generated by mtc on %s_\n\n", r.GeneratedAt.UTC().Format(time.RFC1123))
+	return err
+}
+
+// writeOutput writes the output of a command under a heading, or folded
+// into a <details> block for GitHub
+func writeOutput(w io.Writer, err *error, icon, heading string, result types.CLICommandResult, github bool) {
+	if github {
+		write(w, err, "\n<details>\n<summary>%s%s</summary>\n\n", icon, escapeHTML(heading))
+	} else {
+		write(w, err, "\n### %s%s\n\n", icon, heading)
+	}
+	text := commandOutput(result)
+	fence := codeFence(text)
+	write(w, err, "%sconsole\n%s\n%s\n", fence, text, fence)
+	if github {
+		write(w, err, "\n</details>\n")
+	}
+}
+
+func statusIcon(status string) string {
+	switch status {
+	case "COMPLETED":
+		return "✅"
+	case "FAILED":
+		return "❌"
+	default:
+		return "⚪"
+	}
+}
+
+// escapeCell keeps text from breaking out of a table cell
+func escapeCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+func escapeHTML(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// codeFence returns a fence longer than any run of backticks in s, so the
+// output cannot close the code block it is shown in
+func codeFence(s string) string {
+	longest, run := 0, 0
+	for _, c := range s {
+		if c == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
@@ -0,0 +1,146 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/morethancertified/mtc-cli/internal/types"
+)
+
+// Report formats
+const (
+	JUnit    = "junit"
+	Markdown = "markdown"
+	// GitHub is markdown for a GitHub Actions step summary. It is appended
+	// to $GITHUB_STEP_SUMMARY unless a path is given.
+	GitHub = "github"
+)
+
+// Formats lists the supported report formats
+var Formats = []string{JUnit, Markdown, GitHub}
+
+// Spec is a report requested on the command line as format=path
+type Spec struct {
+	Format string
+	Path   string
+}
+
+// ParseSpec parses a format=path report spec. The path may be left out for
+// the github format.
+func ParseSpec(s string) (Spec, error) {
+	format, path, _ := strings.Cut(s, "=")
+	spec := Spec{Format: format, Path: path}
+
+	switch format {
+	case JUnit, Markdown:
+	case GitHub:
+		if spec.Path == "" {
+			spec.Path = os.Getenv("GITHUB_STEP_SUMMARY")
+		}
+		if spec.Path == "" {
+			return spec, fmt.Errorf("report %q needs a path when GITHUB_STEP_SUMMARY is not set", s)
+		}
+		return spec, nil
+	default:
+		return spec, fmt.Errorf("unknown report format %q, expected %s", format, strings.Join(Formats, ", "))
+	}
+
+	if spec.Path == "" {
+		example := map[string]string{JUnit: "report.xml", Markdown: "report.md"}[format]
+		return spec, fmt.Errorf("report %q needs a path, e.g. %s=%s", s, format, example)
+	}
+	return spec, nil
+}
+
+// Report is the graded state of a lesson
+type Report struct {
+	LessonToken string
+	Lesson      types.Lesson
+	// Results are the validation command results the tasks were graded on,
+	// if known
+	Results     []types.CLICommandResult
+	GeneratedAt time.Time
+}
+
+// Completed counts the tasks that are complete
+func (r Report) Completed() int {
+	count := 0
+	for _, task := range r.Lesson.Tasks {
+		if task.Status == "COMPLETED" {
+			count++
+		}
+	}
+	return count
+}
+
+// failed returns the results of the commands that did not exit
+// successfully
+func (r Report) failed() []types.CLICommandResult {
+	var failed []types.CLICommandResult
+	for _, result := range r.Results {
+		if result.ExitCode != 0 {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Write writes the report in the format of spec. Markdown and JUnit
+// reports replace the file, GitHub step summaries are appended to it.
+func Write(spec Spec, r Report) error {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if spec.Format == GitHub {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+
+	f, err := os.OpenFile(spec.Path, flags, 0644)
+	if err != nil {
+		return err
+	}
+
+	switch spec.Format {
+	case JUnit:
+		err = writeJUnit(f, r)
+	default:
+		err = writeMarkdown(f, r, spec.Format == GitHub)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// ansiPattern matches terminal escape sequences: CSI sequences such as
+// colours, OSC sequences such as titles and hyperlinks, and two-byte escapes
+var ansiPattern = regexp.MustCompile(`\x1b(\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(\x07|\x1b\\)|[@-_])`)
+
+// stripANSI removes terminal escape sequences from command output
+func stripANSI(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
+
+// commandOutput formats a command result the way it would look in a shell,
+// without the escape sequences commands print to colour their output
+func commandOutput(result types.CLICommandResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "$ %s\n", result.Command)
+	if result.Stdout != "" {
+		b.WriteString(stripANSI(result.Stdout) + "\n")
+	}
+	if result.Stderr != "" {
+		b.WriteString(stripANSI(result.Stderr) + "\n")
+	}
+	fmt.Fprintf(&b, "(exit code %d)", result.ExitCode)
+	return b.String()
+}
+
+// write is a helper for writers that only report the first error
+func write(w io.Writer, err *error, format string, a ...interface{}) {
+	if *err == nil {
+		_, *err = fmt.Fprintf(w, format, a...)
+	}
+}
@@ -0,0 +1,137 @@
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/morethancertified/mtc-cli/internal/types"
+)
+
+func testReport(commands []string, results []types.CLICommandResult) Report {
+	return Report{
+		LessonToken: "cabcdefghij",
+		Lesson: types.Lesson{
+			ID:          "lesson-1",
+			CliCommands: commands,
+			Tasks: []types.Task{
+				{Title: "Create the bucket", Status: "COMPLETED"},
+				{Title: "Tag the bucket", Status: "FAILED"},
+			},
+		},
+		Results:     results,
+		GeneratedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestJUnitRoundTrip(t *testing.T) {
+	output := "\x1b[31mError:\x1b[0m tag missing\x00\x07 ]]> \x1b]8;;https://example.com\x07link\x1b]8;;\x07 \xff done"
+	r := testReport([]string{"true", "check-tags"}, []types.CLICommandResult{
+		{Command: "true"},
+		{Command: "check-tags", ExitCode: 1, Stderr: output},
+	})
+
+	var buf bytes.Buffer
+	if err := writeJUnit(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("report is not valid XML: %v\n%s", err, buf.String())
+	}
+
+	if suites.Tests != 2 || suites.Failures != 1 || len(suites.Suites) != 1 {
+		t.Fatalf("got %d tests, %d failures in %d suites, want 2, 1 in 1", suites.Tests, suites.Failures, len(suites.Suites))
+	}
+	failure := suites.Suites[0].Cases[1].Failure
+	if failure == nil {
+		t.Fatal("failed task has no failure")
+	}
+	want := "$ check-tags\nError: tag missing ]]> link \uFFFD done\n(exit code 1)"
+	if failure.Text != want {
+		t.Errorf("failure text = %q, want %q", failure.Text, want)
+	}
+}
+
+func TestJUnitUnpairedCommands(t *testing.T) {
+	r := testReport([]string{"a", "b", "c"}, []types.CLICommandResult{
+		{Command: "a"},
+		{Command: "b", ExitCode: 2, Stderr: "boom"},
+		{Command: "c"},
+	})
+
+	var buf bytes.Buffer
+	if err := writeJUnit(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatal(err)
+	}
+	if len(suites.Suites) != 2 {
+		t.Fatalf("got %d suites, want tasks and commands", len(suites.Suites))
+	}
+	if failure := suites.Suites[0].Cases[1].Failure; failure == nil || failure.Text != "" {
+		t.Errorf("failed task got command output %+v though the commands do not pair up with the tasks", failure)
+	}
+	commands := suites.Suites[1]
+	if commands.Tests != 3 || commands.Failures != 1 || commands.Cases[1].Failure == nil {
+		t.Errorf("commands suite = %+v, want 3 tests with b failing", commands)
+	}
+	if suites.Tests != 5 || suites.Failures != 2 {
+		t.Errorf("totals = %d tests, %d failures, want 5, 2", suites.Tests, suites.Failures)
+	}
+}
+
+func TestMarkdownPairing(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []string
+		results  []types.CLICommandResult
+		want     []string
+		notWant  []string
+	}{
+		{
+			name:     "paired",
+			commands: []string{"true", "check-tags"},
+			results:  []types.CLICommandResult{{Command: "true"}, {Command: "check-tags", ExitCode: 1, Stderr: "no tags"}},
+			want:     []string{"### ❌ Tag the bucket", "$ check-tags\nno tags"},
+			notWant:  []string{"### Validation commands"},
+		},
+		{
+			name:     "more commands than tasks",
+			commands: []string{"a", "b", "c"},
+			results:  []types.CLICommandResult{{Command: "a", ExitCode: 1, Stderr: "no a"}, {Command: "b"}, {Command: "c"}},
+			want:     []string{"### Validation commands", "| ❌ | a | 1 |", "### ❌ a", "$ a\nno a"},
+			notWant:  []string{"### ❌ Tag the bucket"},
+		},
+		{
+			name:     "no results",
+			commands: []string{"true", "check-tags"},
+			notWant:  []string{"### Validation commands", "```"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeMarkdown(&buf, testReport(tt.commands, tt.results), false); err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(buf.String(), s) {
+					t.Errorf("report does not contain %q:\n%s", s, buf.String())
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(buf.String(), s) {
+					t.Errorf("report contains %q:\n%s", s, buf.String())
+				}
+			}
+		})
+	}
+}
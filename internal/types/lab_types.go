@@ -4,11 +4,16 @@ import "time"
 
 // LabFile represents a file in a lab
 type LabFile struct {
-	Path         string     `json:"path"`
-	URL          string     `json:"url"`
-	Size         int64      `json:"size"`
-	LastModified time.Time  `json:"lastModified"`
-	ExpiresAt    time.Time  `json:"expires_at"`
+	Path         string    `json:"path"`
+	URL          string    `json:"url"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"lastModified"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	// Mode is the octal permission string for the file (e.g. "0755"),
+	// empty when the server does not track it
+	Mode string `json:"mode,omitempty"`
+	// LinkTarget is set when the file is a symlink, relative to the file itself
+	LinkTarget string `json:"link_target,omitempty"`
 }
 
 // LabFiles represents the response from the lab files API
@@ -35,9 +40,9 @@ type LabFileURL struct {
 
 // LabInfo represents the lab information
 type LabInfo struct {
-	UserLessonID string    `json:"user_lesson_id"`
-	LessonID     string    `json:"lesson_id"`
-	Title        string    `json:"title"`
+	UserLessonID string `json:"user_lesson_id"`
+	LessonID     string `json:"lesson_id"`
+	Title        string `json:"title"`
 	Course       struct {
 		ID    string `json:"id"`
 		Title string `json:"title"`
@@ -56,4 +61,15 @@ type LabInfo struct {
 	} `json:"s3_paths"`
 }
 
-// No need for LabMetadata since we're not storing metadata
+// LabMetadata is written to the lab directory by init and marks it as
+// belonging to a specific lab
+type LabMetadata struct {
+	UserLessonID  string    `json:"user_lesson_id"`
+	LessonID      string    `json:"lesson_id"`
+	Title         string    `json:"title"`
+	CourseTitle   string    `json:"course_title"`
+	InitializedAt time.Time `json:"initialized_at"`
+	// BootstrappedAt is set once the lab's bootstrap scripts ran successfully
+	BootstrappedAt   *time.Time `json:"bootstrapped_at,omitempty"`
+	BootstrapScripts []string   `json:"bootstrap_scripts,omitempty"`
+}
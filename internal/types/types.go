@@ -37,3 +37,14 @@ type SubmitLessonRequest struct {
 	Type              SubmitLessonRequestType `json:"type"`
 	CliCommandResults []CLICommandResult      `json:"cli_command_results"`
 }
+
+// TaskResults returns the result of the command validating each task. The
+// API does not say which command validates which task, so they are only
+// paired, and ok is true, when the lesson has exactly one command per task
+// and results holds one result per command.
+func (l Lesson) TaskResults(results []CLICommandResult) (paired []CLICommandResult, ok bool) {
+	if len(l.Tasks) == 0 || len(l.CliCommands) != len(l.Tasks) || len(results) != len(l.CliCommands) {
+		return nil, false
+	}
+	return results, true
+}
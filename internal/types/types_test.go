@@ -0,0 +1,34 @@
+package types
+
+import "testing"
+
+func TestTaskResults(t *testing.T) {
+	tasks := []Task{{Title: "one"}, {Title: "two"}}
+	results := []CLICommandResult{{Command: "a"}, {Command: "b"}}
+
+	tests := []struct {
+		name     string
+		lesson   Lesson
+		results  []CLICommandResult
+		wantPair bool
+	}{
+		{"one command per task", Lesson{Tasks: tasks, CliCommands: []string{"a", "b"}}, results, true},
+		{"more commands than tasks", Lesson{Tasks: tasks, CliCommands: []string{"a", "b", "c"}}, append(results, CLICommandResult{Command: "c"}), false},
+		{"fewer commands than tasks", Lesson{Tasks: tasks, CliCommands: []string{"a"}}, results[:1], false},
+		{"results for an older command list", Lesson{Tasks: tasks, CliCommands: []string{"a", "b"}}, results[:1], false},
+		{"no results", Lesson{Tasks: tasks, CliCommands: []string{"a", "b"}}, nil, false},
+		{"no tasks", Lesson{}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paired, ok := tt.lesson.TaskResults(tt.results)
+			if ok != tt.wantPair {
+				t.Fatalf("TaskResults ok = %v, want %v", ok, tt.wantPair)
+			}
+			if ok && len(paired) != len(tt.lesson.Tasks) {
+				t.Errorf("got %d results for %d tasks", len(paired), len(tt.lesson.Tasks))
+			}
+		})
+	}
+}
@@ -0,0 +1,433 @@
+package widgets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/morethancertified/mtc-cli/internal/executor"
+	"github.com/morethancertified/mtc-cli/internal/mtcapi"
+	"github.com/morethancertified/mtc-cli/internal/types"
+)
+
+var (
+	titleStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#00f1ff"))
+	sectionStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#ff00ed"))
+	selectedStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#00f1ff"))
+	dimStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("#626262"))
+	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff5f5f"))
+	okStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("#5fff87"))
+	paneStyle     = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("#626262"))
+)
+
+// SubmitFunc submits the results of a lesson's validation commands for
+// grading and returns the graded lesson
+type SubmitFunc func(results []types.CLICommandResult) (types.Lesson, error)
+
+// RunDashboard shows the full-screen lesson dashboard for a lesson token.
+// Lessons are loaded and reset with client and submitted with submit.
+func RunDashboard(client *mtcapi.MtcApiClient, lessonToken string, submit SubmitFunc) error {
+	m := dashboardModel{
+		client:      client,
+		submit:      submit,
+		lessonToken: lessonToken,
+		spinner:     spinner.New(spinner.WithSpinner(spinner.Dot)),
+		output:      viewport.New(80, 10),
+		status:      "Loading lesson...",
+		busy:        true,
+	}
+
+	_, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	return err
+}
+
+// dashboardEvent is sent from a running job to the dashboard
+type dashboardEvent interface{}
+
+type lessonLoadedMsg struct {
+	lesson types.Lesson
+	action string
+	err    error
+}
+
+type outputLineMsg struct {
+	line   string
+	stderr bool
+}
+
+type commandStartMsg struct {
+	index int
+}
+
+type commandDoneMsg struct {
+	index  int
+	result types.CLICommandResult
+}
+
+type jobDoneMsg struct{}
+
+type dashboardModel struct {
+	client      *mtcapi.MtcApiClient
+	submit      SubmitFunc
+	lessonToken string
+
+	lesson   types.Lesson
+	results  []*types.CLICommandResult
+	cursor   int
+	running  int
+	lines    []string
+	events   chan dashboardEvent
+	cancel   context.CancelFunc
+	busy     bool
+	confirm  bool
+	showLast bool
+	status   string
+
+	spinner spinner.Model
+	output  viewport.Model
+	width   int
+	height  int
+}
+
+func (m dashboardModel) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, m.loadLesson("load"))
+}
+
+func (m dashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.resize()
+		return m, nil
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case lessonLoadedMsg:
+		m.busy = false
+		if msg.err != nil {
+			m.status = errorStyle.Render(fmt.Sprintf("Error during %s: %s", msg.action, msg.err))
+			return m, nil
+		}
+		m.lesson = msg.lesson
+		if len(m.results) != len(m.lesson.CliCommands) {
+			m.results = make([]*types.CLICommandResult, len(m.lesson.CliCommands))
+		}
+		if m.cursor >= len(m.lesson.CliCommands) {
+			m.cursor = 0
+		}
+		switch msg.action {
+		case "submit":
+			m.status = okStyle.Render("Grading complete!")
+		case "reset":
+			m.results = make([]*types.CLICommandResult, len(m.lesson.CliCommands))
+			m.status = okStyle.Render("Lesson reset!")
+		default:
+			m.status = "Lesson loaded."
+		}
+		m.resize()
+		return m, nil
+
+	case outputLineMsg:
+		line := msg.line
+		if msg.stderr {
+			line = errorStyle.Render(line)
+		}
+		m.appendOutput(line)
+		return m, waitForEvent(m.events)
+
+	case commandStartMsg:
+		m.running = msg.index
+		return m, waitForEvent(m.events)
+
+	case commandDoneMsg:
+		result := msg.result
+		m.results[msg.index] = &result
+		m.appendOutput(dimStyle.Render(fmt.Sprintf("exit code %d", result.ExitCode)))
+		return m, waitForEvent(m.events)
+
+	case lessonLoadedMsgFromJob:
+		m.events = nil
+		return m.Update(lessonLoadedMsg(msg))
+
+	case jobDoneMsg:
+		m.events = nil
+		m.busy = false
+		m.running = -1
+		m.status = "Commands finished."
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// lessonLoadedMsgFromJob ends a job that finished by reloading the lesson
+type lessonLoadedMsgFromJob lessonLoadedMsg
+
+func (m dashboardModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.confirm {
+		m.confirm = false
+		if msg.String() == "y" {
+			m.busy = true
+			m.status = "Resetting lesson..."
+			return m, m.loadLesson("reset")
+		}
+		m.status = "Reset cancelled."
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+c", "q":
+		if m.cancel != nil {
+			m.cancel()
+		}
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.lesson.CliCommands)-1 {
+			m.cursor++
+		}
+	case "pgup", "pgdown":
+		var cmd tea.Cmd
+		m.output, cmd = m.output.Update(msg)
+		return m, cmd
+	case "l":
+		m.showLast = !m.showLast
+		m.refreshOutput()
+	case "enter", "r":
+		if !m.busy && len(m.lesson.CliCommands) > 0 {
+			return m.startJob([]int{m.cursor}, false)
+		}
+	case "a":
+		if !m.busy && len(m.lesson.CliCommands) > 0 {
+			return m.startJob(m.allCommands(), false)
+		}
+	case "s":
+		if !m.busy && len(m.lesson.CliCommands) > 0 {
+			return m.startJob(m.allCommands(), true)
+		}
+	case "x":
+		if !m.busy {
+			m.confirm = true
+			m.status = errorStyle.Render("Reset all progress for this lesson? (y/n)")
+		}
+	case "u":
+		if !m.busy {
+			m.busy = true
+			m.status = "Refreshing lesson..."
+			return m, m.loadLesson("refresh")
+		}
+	}
+
+	return m, nil
+}
+
+func (m dashboardModel) allCommands() []int {
+	indices := make([]int, len(m.lesson.CliCommands))
+	for i := range indices {
+		indices[i] = i
+	}
+	return indices
+}
+
+// startJob runs the given commands one after the other in the background,
+// streaming their output, and submits all results when submit is set
+func (m dashboardModel) startJob(indices []int, submit bool) (tea.Model, tea.Cmd) {
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan dashboardEvent, 64)
+
+	m.events = events
+	m.cancel = cancel
+	m.busy = true
+	m.showLast = false
+	m.lines = nil
+	m.running = indices[0]
+	if submit {
+		m.status = "Running validation commands before submitting..."
+	} else {
+		m.status = "Running..."
+	}
+	m.refreshOutput()
+
+	commands := m.lesson.CliCommands
+	submitLesson := m.submit
+	go func() {
+		defer close(events)
+		defer cancel()
+
+		// send drops the event once the dashboard has quit, since nothing
+		// reads events any more
+		send := func(event dashboardEvent) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		var results []types.CLICommandResult
+		for _, i := range indices {
+			if !send(commandStartMsg{index: i}) || !send(outputLineMsg{line: sectionStyle.Render("$ " + commands[i])}) {
+				return
+			}
+			result := executor.RunStreaming(ctx, commands[i], func(line string, stderr bool) {
+				send(outputLineMsg{line: line, stderr: stderr})
+			})
+			results = append(results, result)
+			if !send(commandDoneMsg{index: i, result: result}) {
+				return
+			}
+		}
+
+		if submit {
+			if !send(outputLineMsg{line: dimStyle.Render("Submitting results for grading...")}) {
+				return
+			}
+			lesson, err := submitLesson(results)
+			send(lessonLoadedMsgFromJob{lesson: lesson, action: "submit", err: err})
+		}
+	}()
+
+	return m, tea.Batch(m.spinner.Tick, waitForEvent(events))
+}
+
+func waitForEvent(events chan dashboardEvent) tea.Cmd {
+	if events == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		event, ok := <-events
+		if !ok {
+			return jobDoneMsg{}
+		}
+		return event
+	}
+}
+
+func (m dashboardModel) loadLesson(action string) tea.Cmd {
+	client := m.client
+	lessonToken := m.lessonToken
+	return func() tea.Msg {
+		var lesson types.Lesson
+		var err error
+		if action == "reset" {
+			lesson, err = client.ResetLesson(lessonToken)
+		} else {
+			lesson, err = client.GetLesson(lessonToken)
+		}
+		return lessonLoadedMsg{lesson: lesson, action: action, err: err}
+	}
+}
+
+func (m *dashboardModel) appendOutput(line string) {
+	m.lines = append(m.lines, line)
+	if !m.showLast {
+		m.refreshOutput()
+	}
+}
+
+// refreshOutput fills the output pane with either the live output or the
+// last result of every command
+func (m *dashboardModel) refreshOutput() {
+	if !m.showLast {
+		m.output.SetContent(strings.Join(m.lines, "\n"))
+		m.output.GotoBottom()
+		return
+	}
+
+	var b strings.Builder
+	for i, command := range m.lesson.CliCommands {
+		b.WriteString(sectionStyle.Render("$ "+command) + "\n")
+		result := m.results[i]
+		if result == nil {
+			b.WriteString(dimStyle.Render("not run yet") + "\n\n")
+			continue
+		}
+		b.WriteString(dimStyle.Render(fmt.Sprintf("exit code %d", result.ExitCode)) + "\n")
+		if result.Stdout != "" {
+			b.WriteString(result.Stdout + "\n")
+		}
+		if result.Stderr != "" {
+			b.WriteString(errorStyle.Render(result.Stderr) + "\n")
+		}
+		b.WriteString("\n")
+	}
+	m.output.SetContent(b.String())
+	m.output.GotoTop()
+}
+
+func (m *dashboardModel) resize() {
+	if m.width == 0 {
+		return
+	}
+	// Header, task and command lists, pane borders, status and help lines
+	used := 4 + len(m.lesson.Tasks) + len(m.lesson.CliCommands) + 4 + 3
+	height := m.height - used
+	if height < 3 {
+		height = 3
+	}
+	m.output.Width = m.width - 2
+	m.output.Height = height
+	m.refreshOutput()
+}
+
+func (m dashboardModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("MTC lesson dashboard") + "  " + dimStyle.Render(m.lessonToken) + "\n\n")
+
+	b.WriteString(sectionStyle.Render("TASKS") + "\n")
+	for _, task := range m.lesson.Tasks {
+		b.WriteString(fmt.Sprintf("  %s %s\n", taskStatusIcon(task.Status), task.Title))
+	}
+
+	b.WriteString("\n" + sectionStyle.Render("VALIDATION COMMANDS") + "\n")
+	for i, command := range m.lesson.CliCommands {
+		cursor := "  "
+		line := command
+		if i == m.cursor {
+			cursor = "> "
+			line = selectedStyle.Render(command)
+		}
+		marker := dimStyle.Render("·")
+		if m.busy && m.events != nil && i == m.running {
+			marker = m.spinner.View()
+		} else if i < len(m.results) && m.results[i] != nil {
+			if m.results[i].ExitCode == 0 {
+				marker = okStyle.Render("✓")
+			} else {
+				marker = errorStyle.Render("✗")
+			}
+		}
+		b.WriteString(fmt.Sprintf("%s%s %s\n", cursor, marker, line))
+	}
+
+	title := "OUTPUT"
+	if m.showLast {
+		title = "LAST RESULTS"
+	}
+	b.WriteString("\n" + sectionStyle.Render(title) + "\n")
+	b.WriteString(paneStyle.Render(m.output.View()) + "\n")
+
+	status := m.status
+	if m.busy {
+		status = m.spinner.View() + " " + status
+	}
+	b.WriteString(status + "\n")
+	b.WriteString(helpStyle("↑/↓ select • enter/r run command • a run all • s submit • x reset • l last results • u refresh • q quit"))
+
+	return b.String()
+}
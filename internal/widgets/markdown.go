@@ -0,0 +1,119 @@
+package widgets
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/morethancertified/mtc-cli/internal/output"
+)
+
+var (
+	h1Style    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#00f1ff")).Underline(true)
+	h2Style    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#00f1ff"))
+	h3Style    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#ff00ed"))
+	codeStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff00ed"))
+	blockStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#d0d0d0")).PaddingLeft(4)
+	quoteStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#626262")).PaddingLeft(2)
+	boldStyle  = lipgloss.NewStyle().Bold(true)
+	ruleStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#626262"))
+)
+
+var (
+	inlineCodeRe = regexp.MustCompile("`([^`]+)`")
+	boldRe       = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	linkRe       = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+	listRe       = regexp.MustCompile(`^(\s*)([-*+]|\d+\.)\s+(.*)$`)
+)
+
+// RenderMarkdown renders a markdown document for the terminal, wrapping
+// paragraphs at width. Only the subset of markdown used by lab READMEs is
+// styled; anything else is printed as is.
+func RenderMarkdown(md string, width int) string {
+	var out []string
+	var paragraph []string
+	inCode := false
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			text := renderInline(strings.Join(paragraph, " "))
+			out = append(out, lipgloss.NewStyle().Width(width).Render(text), "")
+			paragraph = nil
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			flush()
+			if len(out) > 0 && out[len(out)-1] != "" {
+				out = append(out, "")
+			}
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			out = append(out, blockStyle.Render(line))
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			flush()
+			if len(out) > 0 && out[len(out)-1] != "" {
+				out = append(out, "")
+			}
+		case strings.HasPrefix(trimmed, "# "):
+			flush()
+			out = append(out, h1Style.Render(strings.TrimPrefix(trimmed, "# ")), "")
+		case strings.HasPrefix(trimmed, "## "):
+			flush()
+			out = append(out, h2Style.Render(strings.TrimPrefix(trimmed, "## ")), "")
+		case strings.HasPrefix(trimmed, "#"):
+			flush()
+			out = append(out, h3Style.Render(strings.TrimSpace(strings.TrimLeft(trimmed, "#"))), "")
+		case trimmed == "---" || trimmed == "***":
+			flush()
+			out = append(out, ruleStyle.Render(strings.Repeat(glyph("─", "-"), width)), "")
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+			text := renderInline(strings.TrimSpace(strings.TrimPrefix(trimmed, ">")))
+			out = append(out, quoteStyle.Width(width).Render(glyph("│", "|")+" "+text))
+		case listRe.MatchString(line):
+			flush()
+			m := listRe.FindStringSubmatch(line)
+			bullet := glyph("•", "*")
+			if strings.HasSuffix(m[2], ".") {
+				bullet = m[2]
+			}
+			indent := len(m[1]) + 2
+			item := lipgloss.NewStyle().Width(width - indent - 2).Render(renderInline(m[3]))
+			out = append(out, lipgloss.JoinHorizontal(lipgloss.Top, strings.Repeat(" ", indent)+bullet+" ", item))
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+
+	return strings.TrimRight(strings.Join(out, "\n"), "\n")
+}
+
+func renderInline(text string) string {
+	text = linkRe.ReplaceAllString(text, "$1 ($2)")
+	text = inlineCodeRe.ReplaceAllStringFunc(text, func(s string) string {
+		return codeStyle.Render(strings.Trim(s, "`"))
+	})
+	text = boldRe.ReplaceAllStringFunc(text, func(s string) string {
+		return boldStyle.Render(strings.Trim(s, "*"))
+	})
+	return text
+}
+
+// glyph returns fancy, or its ASCII replacement in plain mode
+func glyph(fancy, ascii string) string {
+	if output.Plain() {
+		return ascii
+	}
+	return fancy
+}
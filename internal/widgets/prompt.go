@@ -0,0 +1,93 @@
+package widgets
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/erikgeiser/promptkit/confirmation"
+	"github.com/erikgeiser/promptkit/selection"
+	"github.com/morethancertified/mtc-cli/internal/output"
+)
+
+// stdin is shared by the line-based prompts so input buffered by one prompt
+// is not lost to the next
+var stdin = bufio.NewReader(os.Stdin)
+
+// Confirm asks a yes/no question. In plain mode it reads a line from stdin,
+// otherwise it shows an interactive prompt.
+func Confirm(question string, defaultYes bool) (bool, error) {
+	if !output.Plain() {
+		value := confirmation.No
+		if defaultYes {
+			value = confirmation.Yes
+		}
+		input := confirmation.New(question, value)
+		input.Output = output.Human
+		return input.RunPrompt()
+	}
+
+	hint := "[y/N]"
+	if defaultYes {
+		hint = "[Y/n]"
+	}
+	for {
+		output.Printf("%s %s: ", question, hint)
+		answer, err := readLine()
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return defaultYes, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		output.Println("Please answer y or n.")
+	}
+}
+
+// Select asks the user to choose one of options. In plain mode the options
+// are numbered and the number is read from stdin, otherwise it shows an
+// interactive list.
+func Select(question string, options []string) (string, error) {
+	if !output.Plain() {
+		sp := selection.New(question, options)
+		sp.Output = output.Human
+		return sp.RunPrompt()
+	}
+
+	output.Println(question)
+	for i, option := range options {
+		output.Printf("  %d) %s\n", i+1, option)
+	}
+	for {
+		output.Printf("Enter a number [1-%d]: ", len(options))
+		answer, err := readLine()
+		if err != nil {
+			return "", err
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+			return options[n-1], nil
+		}
+		output.Printf("Please enter a number between 1 and %d.\n", len(options))
+	}
+}
+
+// readLine reads one trimmed line from stdin
+func readLine() (string, error) {
+	line, err := stdin.ReadString('\n')
+	if errors.Is(err, io.EOF) && line == "" {
+		return "", fmt.Errorf("no answer given, stdin was closed")
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
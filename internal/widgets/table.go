@@ -0,0 +1,141 @@
+package widgets
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/morethancertified/mtc-cli/internal/output"
+	"github.com/morethancertified/mtc-cli/internal/types"
+)
+
+const (
+	tableGap          = 2
+	minTaskTitleWidth = 20
+	defaultTableWidth = 80
+)
+
+var (
+	tableHeaderStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#00f1ff"))
+	changedStyle     = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#ff00ed"))
+)
+
+// RenderTaskTable renders tasks as a table of status, title and last update
+// that fits in width columns, wrapping long titles. Tasks whose status
+// differs from the same task in previous are highlighted; pass nil when
+// there is nothing to compare with.
+func RenderTaskTable(tasks []types.Task, previous []types.Task, width int) string {
+	if width <= 0 {
+		width = defaultTableWidth
+	}
+
+	previousStatus := map[string]string{}
+	for _, task := range previous {
+		previousStatus[task.ID] = task.Status
+	}
+	changed := func(task types.Task) bool {
+		status, ok := previousStatus[task.ID]
+		return previous != nil && (!ok || status != task.Status)
+	}
+
+	type row struct {
+		status, title, updated string
+		changed                bool
+	}
+	rows := make([]row, len(tasks))
+	statusWidth := lipgloss.Width("STATUS")
+	updatedWidth := lipgloss.Width("LAST UPDATED")
+	for i, task := range tasks {
+		rows[i] = row{
+			status:  taskStatusIcon(task.Status) + " " + task.Status,
+			title:   task.Title,
+			updated: "-",
+			changed: changed(task),
+		}
+		if !task.UpdatedAt.IsZero() {
+			rows[i].updated = task.UpdatedAt.Local().Format("2006-01-02 15:04")
+		}
+		statusWidth = max(statusWidth, lipgloss.Width(rows[i].status))
+		updatedWidth = max(updatedWidth, lipgloss.Width(rows[i].updated))
+	}
+
+	changedMarker := "→"
+	if output.Plain() {
+		changedMarker = "*"
+	}
+	markerWidth := 0
+	if previous != nil {
+		markerWidth = lipgloss.Width(changedMarker) + 1
+	}
+	titleWidth := width - markerWidth - statusWidth - updatedWidth - 2*tableGap
+	longestTitle := lipgloss.Width("TASK")
+	for _, r := range rows {
+		longestTitle = max(longestTitle, lipgloss.Width(r.title))
+	}
+	titleWidth = max(min(titleWidth, longestTitle), minTaskTitleWidth)
+
+	cell := func(text string, width int, style lipgloss.Style) string {
+		return style.Copy().Width(width).Render(text)
+	}
+	gap := strings.Repeat(" ", tableGap)
+	line := func(marker, status, title, updated string, style, statusStyle lipgloss.Style) string {
+		cells := []string{}
+		if markerWidth > 0 {
+			cells = append(cells, cell(marker, markerWidth, style))
+		}
+		cells = append(cells,
+			cell(status, statusWidth, statusStyle), gap,
+			cell(title, titleWidth, style), gap,
+			cell(updated, updatedWidth, style),
+		)
+		return lipgloss.JoinHorizontal(lipgloss.Top, cells...)
+	}
+
+	var lines []string
+	lines = append(lines, line("", "STATUS", "TASK", "LAST UPDATED", tableHeaderStyle, tableHeaderStyle))
+	for i, r := range rows {
+		style := lipgloss.NewStyle()
+		marker := ""
+		if r.changed {
+			style = changedStyle
+			marker = changedMarker
+		}
+		lines = append(lines, line(marker, r.status, r.title, r.updated, style, taskStatusStyle(tasks[i].Status)))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// taskStatusStyle colours a task status
+func taskStatusStyle(status string) lipgloss.Style {
+	switch status {
+	case "COMPLETED":
+		return okStyle
+	case "FAILED":
+		return errorStyle
+	default:
+		return lipgloss.NewStyle()
+	}
+}
+
+// taskStatusIcon returns the marker shown next to a task
+func taskStatusIcon(status string) string {
+	if output.Plain() {
+		switch status {
+		case "COMPLETED":
+			return "[x]"
+		case "FAILED":
+			return "[!]"
+		default:
+			return "[ ]"
+		}
+	}
+
+	switch status {
+	case "COMPLETED":
+		return "✅"
+	case "FAILED":
+		return "❌"
+	default:
+		return "⚪"
+	}
+}
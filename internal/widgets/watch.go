@@ -0,0 +1,375 @@
+package widgets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fsnotify/fsnotify"
+	"github.com/morethancertified/mtc-cli/internal/executor"
+	"github.com/morethancertified/mtc-cli/internal/lab"
+	"github.com/morethancertified/mtc-cli/internal/output"
+	"github.com/morethancertified/mtc-cli/internal/types"
+)
+
+// WatchDebounce is how long the lab must be quiet after a change before the
+// validation commands are run again
+const WatchDebounce = 500 * time.Millisecond
+
+// RunWatch watches dir and re-runs the lesson's validation commands locally
+// whenever files change. Results are submitted with submit when the user
+// presses s, or after every run with new results when autoSubmit is set. It
+// returns the lesson as last seen from the API.
+func RunWatch(submit SubmitFunc, lessonToken, dir string, lesson types.Lesson, autoSubmit bool) (types.Lesson, error) {
+	watcher, err := lab.Watch(dir)
+	if err != nil {
+		return lesson, fmt.Errorf("watching %s: %w", dir, err)
+	}
+	defer watcher.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := watchModel{
+		submit:      submit,
+		lessonToken: lessonToken,
+		dir:         dir,
+		lesson:      lesson,
+		autoSubmit:  autoSubmit,
+		watcher:     watcher,
+		ctx:         ctx,
+		spinner:     spinner.New(spinner.WithSpinner(spinner.Dot)),
+		plain:       output.Plain(),
+		running:     true,
+		status:      "Running validation commands...",
+	}
+
+	final, err := tea.NewProgram(m).Run()
+	if err != nil {
+		return lesson, err
+	}
+	return final.(watchModel).lesson, nil
+}
+
+type fileChangedMsg struct {
+	path string
+}
+
+type watchErrorMsg struct {
+	err error
+}
+
+type debounceMsg struct {
+	generation int
+	// pending is set for the re-run of a change made while commands ran
+	pending bool
+}
+
+type validationDoneMsg struct {
+	results []types.CLICommandResult
+}
+
+type submittedMsg struct {
+	lesson types.Lesson
+	err    error
+}
+
+type watchModel struct {
+	submit      SubmitFunc
+	lessonToken string
+	dir         string
+	lesson      types.Lesson
+	autoSubmit  bool
+	watcher     *fsnotify.Watcher
+	ctx         context.Context
+
+	results    []types.CLICommandResult
+	submitted  []types.CLICommandResult
+	generation int
+	changed    string
+	// pending is the last file changed while the commands ran, they are
+	// run again once they finish
+	pending    string
+	rerun      bool
+	running    bool
+	submitting bool
+	status     string
+
+	// plain prints status changes as lines instead of redrawing the screen
+	plain   bool
+	spinner spinner.Model
+}
+
+func (m watchModel) Init() tea.Cmd {
+	if m.plain {
+		intro := tea.Sequence(
+			tea.Println(fmt.Sprintf("Watching %s. %s.", m.dir, m.help())),
+			tea.Println(m.status),
+		)
+		return tea.Batch(intro, m.validate(), m.waitForChange())
+	}
+	return tea.Batch(m.spinner.Tick, m.validate(), m.waitForChange())
+}
+
+func (m watchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q":
+			return m, tea.Quit
+		case "r":
+			if !m.running {
+				return m.startRun("Re-running validation commands...")
+			}
+		case "s":
+			if !m.running && !m.submitting && m.results != nil {
+				return m.startSubmit()
+			}
+		}
+		return m, nil
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case fileChangedMsg:
+		if m.running {
+			m.pending = msg.path
+			return m, m.waitForChange()
+		}
+		m.changed = msg.path
+		return m, tea.Batch(m.waitForChange(), m.debounce(false))
+
+	case debounceMsg:
+		if msg.generation != m.generation {
+			return m, nil
+		}
+		if m.running {
+			m.pending = m.changed
+			return m, nil
+		}
+		m.rerun = msg.pending
+		rel, err := filepath.Rel(m.dir, m.changed)
+		if err != nil {
+			rel = m.changed
+		}
+		return m.startRun(fmt.Sprintf("%s changed, running validation commands...", rel))
+
+	case watchErrorMsg:
+		report := m.setStatus(errorStyle.Render(fmt.Sprintf("Error watching files: %s", msg.err)))
+		return m, tea.Batch(report, m.waitForChange())
+
+	case validationDoneMsg:
+		m.running = false
+		previous := m.results
+		m.results = msg.results
+
+		// Commands that write to the lab themselves would trigger runs
+		// forever, so a re-run for such changes that did not change the
+		// results does not start another one
+		if m.pending != "" && !(m.rerun && reflect.DeepEqual(previous, m.results)) {
+			m.changed, m.pending = m.pending, ""
+			report := tea.Sequence(m.report(), m.setStatus(fmt.Sprintf("%d/%d commands pass locally. Files changed while running, running again...", passing(m.results), len(m.results))))
+			return m, tea.Batch(report, m.debounce(true))
+		}
+		m.pending, m.rerun = "", false
+
+		report := tea.Sequence(m.report(), m.setStatus(fmt.Sprintf("%d/%d commands pass locally. Watching for changes...", passing(m.results), len(m.results))))
+		if m.autoSubmit && !reflect.DeepEqual(m.results, m.submitted) {
+			model, submit := m.startSubmit()
+			return model, tea.Sequence(report, submit)
+		}
+		return m, report
+
+	case submittedMsg:
+		m.submitting = false
+		if msg.err != nil {
+			return m, m.setStatus(errorStyle.Render(fmt.Sprintf("Error submitting lesson: %s", msg.err)))
+		}
+		m.lesson = msg.lesson
+		return m, tea.Sequence(m.report(), m.setStatus(okStyle.Render("Grading complete!")+" Watching for changes..."))
+	}
+
+	return m, nil
+}
+
+// debounce runs the commands once no file changed for WatchDebounce
+func (m *watchModel) debounce(pending bool) tea.Cmd {
+	m.generation++
+	generation := m.generation
+	return tea.Tick(WatchDebounce, func(time.Time) tea.Msg {
+		return debounceMsg{generation: generation, pending: pending}
+	})
+}
+
+func (m watchModel) startRun(status string) (tea.Model, tea.Cmd) {
+	m.running = true
+	return m, tea.Batch(m.setStatus(status), m.tick(), m.validate())
+}
+
+func (m watchModel) startSubmit() (tea.Model, tea.Cmd) {
+	m.submitting = true
+	m.submitted = m.results
+
+	submit := m.submit
+	results := m.results
+	return m, tea.Batch(m.setStatus("Submitting results for grading..."), m.tick(), func() tea.Msg {
+		lesson, err := submit(results)
+		return submittedMsg{lesson: lesson, err: err}
+	})
+}
+
+// setStatus changes the status line, which is printed in plain mode
+func (m *watchModel) setStatus(status string) tea.Cmd {
+	m.status = status
+	if m.plain {
+		return tea.Println(status)
+	}
+	return nil
+}
+
+// report prints the tasks and command results in plain mode
+func (m watchModel) report() tea.Cmd {
+	if !m.plain {
+		return nil
+	}
+	return tea.Println(strings.TrimRight(m.body(), "\n"))
+}
+
+// tick starts the spinner, which is not shown in plain mode
+func (m watchModel) tick() tea.Cmd {
+	if m.plain {
+		return nil
+	}
+	return m.spinner.Tick
+}
+
+func (m watchModel) help() string {
+	if m.autoSubmit {
+		return "Results are submitted automatically, press r to re-run or q to quit"
+	}
+	return "Press s to submit, r to re-run or q to quit"
+}
+
+// validate runs every validation command in the lab directory
+func (m watchModel) validate() tea.Cmd {
+	ctx := m.ctx
+	commands := m.lesson.CliCommands
+	return func() tea.Msg {
+		results := []types.CLICommandResult{}
+		for _, command := range commands {
+			results = append(results, executor.RunStreaming(ctx, command, nil))
+		}
+		return validationDoneMsg{results: results}
+	}
+}
+
+// waitForChange waits for the next relevant file system event. New
+// directories are added to the watcher as they appear.
+func (m watchModel) waitForChange() tea.Cmd {
+	watcher := m.watcher
+	dir := m.dir
+	return func() tea.Msg {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return nil
+				}
+				if lab.Ignored(dir, event.Name) || event.Op == fsnotify.Chmod {
+					continue
+				}
+				if event.Op&fsnotify.Create != 0 {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						if err := lab.AddTree(watcher, dir, event.Name); err != nil {
+							return watchErrorMsg{err: err}
+						}
+					}
+				}
+				return fileChangedMsg{path: event.Name}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return nil
+				}
+				return watchErrorMsg{err: err}
+			}
+		}
+	}
+}
+
+// passing counts the commands that exited successfully
+func passing(results []types.CLICommandResult) int {
+	count := 0
+	for _, result := range results {
+		if result.ExitCode == 0 {
+			count++
+		}
+	}
+	return count
+}
+
+func (m watchModel) View() string {
+	if m.plain {
+		return ""
+	}
+
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Watching "+m.dir) + "  " + dimStyle.Render(m.lessonToken) + "\n\n")
+	b.WriteString(m.body())
+
+	status := m.status
+	if m.running || m.submitting {
+		status = m.spinner.View() + " " + status
+	}
+	b.WriteString("\n" + status + "\n")
+	b.WriteString(helpStyle(m.help()) + "\n")
+
+	return b.String()
+}
+
+// body lists the tasks and validation commands with their latest results
+func (m watchModel) body() string {
+	var b strings.Builder
+
+	// A local verdict per task is only shown when the commands pair up with
+	// the tasks
+	results, paired := m.lesson.TaskResults(m.results)
+
+	b.WriteString(sectionStyle.Render("TASKS") + "\n")
+	for i, task := range m.lesson.Tasks {
+		line := fmt.Sprintf("  %s %s", taskStatusIcon(task.Status), task.Title)
+		if paired && task.Status != "COMPLETED" {
+			if results[i].ExitCode == 0 {
+				line += "  " + okStyle.Render("would pass")
+			} else {
+				line += "  " + errorStyle.Render("would fail")
+			}
+		}
+		b.WriteString(line + "\n")
+	}
+
+	b.WriteString("\n" + sectionStyle.Render("VALIDATION COMMANDS") + "\n")
+	for i, command := range m.lesson.CliCommands {
+		marker := dimStyle.Render(glyph("·", "-"))
+		if m.running {
+			marker = m.spinner.View()
+		} else if i < len(m.results) {
+			if m.results[i].ExitCode == 0 {
+				marker = okStyle.Render(glyph("✓", "PASS"))
+			} else {
+				marker = errorStyle.Render(glyph("✗", "FAIL"))
+			}
+		}
+		b.WriteString(fmt.Sprintf("  %s %s\n", marker, command))
+	}
+
+	return b.String()
+}
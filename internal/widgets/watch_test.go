@@ -0,0 +1,151 @@
+package widgets
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/morethancertified/mtc-cli/internal/types"
+)
+
+// update feeds msg to m and returns the new model
+func update(t *testing.T, m watchModel, msg tea.Msg) watchModel {
+	t.Helper()
+	model, _ := m.Update(msg)
+	return model.(watchModel)
+}
+
+func testWatchModel() watchModel {
+	return watchModel{
+		dir:     "/lab",
+		lesson:  types.Lesson{CliCommands: []string{"check"}},
+		running: true,
+		submit: func([]types.CLICommandResult) (types.Lesson, error) {
+			return types.Lesson{}, nil
+		},
+	}
+}
+
+var (
+	failing = []types.CLICommandResult{{Command: "check", ExitCode: 1}}
+	passed  = []types.CLICommandResult{{Command: "check"}}
+)
+
+func TestWatchDebouncesChanges(t *testing.T) {
+	m := update(t, testWatchModel(), validationDoneMsg{results: failing})
+	if m.running {
+		t.Fatal("still running after the commands finished")
+	}
+
+	m = update(t, m, fileChangedMsg{path: "/lab/main.tf"})
+	m = update(t, m, fileChangedMsg{path: "/lab/vars.tf"})
+	if m.running || m.generation != 2 {
+		t.Fatalf("running = %v, generation = %d after two changes, want not running and 2", m.running, m.generation)
+	}
+
+	// Only the debounce of the last change starts a run
+	m = update(t, m, debounceMsg{generation: 1})
+	if m.running {
+		t.Fatal("a stale debounce started a run")
+	}
+	m = update(t, m, debounceMsg{generation: 2})
+	if !m.running || m.rerun {
+		t.Fatalf("running = %v, rerun = %v after the debounce, want a normal run", m.running, m.rerun)
+	}
+	if m.changed != "/lab/vars.tf" {
+		t.Errorf("changed = %q, want the last changed file", m.changed)
+	}
+}
+
+func TestWatchRerunsChangesMadeWhileRunning(t *testing.T) {
+	m := update(t, testWatchModel(), fileChangedMsg{path: "/lab/main.tf"})
+	if m.pending != "/lab/main.tf" || m.generation != 0 {
+		t.Fatalf("pending = %q, generation = %d, want the change to wait for the run", m.pending, m.generation)
+	}
+
+	m = update(t, m, validationDoneMsg{results: failing})
+	if m.running || m.pending != "" || m.changed != "/lab/main.tf" || m.generation != 1 {
+		t.Fatalf("after the run: running = %v, pending = %q, changed = %q, generation = %d, want a debounced re-run of main.tf", m.running, m.pending, m.changed, m.generation)
+	}
+
+	m = update(t, m, debounceMsg{generation: 1, pending: true})
+	if !m.running || !m.rerun {
+		t.Fatalf("running = %v, rerun = %v, want the pending re-run", m.running, m.rerun)
+	}
+	m = update(t, m, validationDoneMsg{results: passed})
+	if m.running || m.rerun || m.results[0].ExitCode != 0 {
+		t.Errorf("after the re-run: running = %v, rerun = %v, results = %+v", m.running, m.rerun, m.results)
+	}
+}
+
+func TestWatchDebounceWhileRunning(t *testing.T) {
+	m := update(t, testWatchModel(), validationDoneMsg{results: failing})
+	m = update(t, m, fileChangedMsg{path: "/lab/main.tf"})
+	m.running = true // a run started with r before the debounce fired
+
+	m = update(t, m, debounceMsg{generation: m.generation})
+	if m.pending != "/lab/main.tf" {
+		t.Fatalf("pending = %q, want the debounced change to wait for the run", m.pending)
+	}
+	m = update(t, m, validationDoneMsg{results: failing})
+	if m.pending != "" || m.generation != 2 {
+		t.Errorf("pending = %q, generation = %d, want a re-run scheduled", m.pending, m.generation)
+	}
+}
+
+func TestWatchStopsRerunLoop(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []types.CLICommandResult
+		again   bool
+	}{
+		{"same results", failing, false},
+		{"new results", passed, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := update(t, testWatchModel(), validationDoneMsg{results: failing})
+			m.running, m.rerun = true, true
+
+			// The commands changed a file in the lab while they ran
+			m = update(t, m, fileChangedMsg{path: "/lab/state"})
+			generation := m.generation
+			m = update(t, m, validationDoneMsg{results: tt.results})
+
+			if again := m.generation != generation; again != tt.again {
+				t.Errorf("scheduled another run = %v, want %v", again, tt.again)
+			}
+			if m.pending != "" {
+				t.Errorf("pending = %q after the run, want it cleared", m.pending)
+			}
+			if !tt.again && m.rerun {
+				t.Error("rerun still set after the loop was stopped")
+			}
+		})
+	}
+}
+
+func TestWatchAutoSubmit(t *testing.T) {
+	m := testWatchModel()
+	m.autoSubmit = true
+
+	m = update(t, m, fileChangedMsg{path: "/lab/main.tf"})
+	m = update(t, m, validationDoneMsg{results: failing})
+	if m.submitting {
+		t.Fatal("submitted results that are about to be re-run")
+	}
+
+	m = update(t, m, debounceMsg{generation: m.generation, pending: true})
+	m = update(t, m, validationDoneMsg{results: passed})
+	if !m.submitting {
+		t.Fatal("new results were not submitted")
+	}
+
+	m = update(t, m, submittedMsg{lesson: m.lesson})
+	m = update(t, m, fileChangedMsg{path: "/lab/main.tf"})
+	m = update(t, m, debounceMsg{generation: m.generation})
+	m = update(t, m, validationDoneMsg{results: passed})
+	if m.submitting {
+		t.Error("unchanged results were submitted again")
+	}
+}